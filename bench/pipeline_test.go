@@ -0,0 +1,116 @@
+package bench
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/Mario-Jimenez/gocompiler/identification"
+	"github.com/Mario-Jimenez/gocompiler/parser"
+	"github.com/Mario-Jimenez/gocompiler/visitor/codegenerator"
+	"github.com/Mario-Jimenez/gocompiler/visitor/contextual"
+	"github.com/Mario-Jimenez/gocompiler/visitor/graph"
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// corpus is every representative Monkey program under testdata/, loaded
+// once and reused across benchmark iterations so file I/O never shows up
+// in the numbers.
+var corpus = loadCorpus("small.monkey", "medium.monkey", "large.monkey")
+
+func loadCorpus(names ...string) map[string]string {
+	programs := make(map[string]string, len(names))
+
+	for _, name := range names {
+		data, err := ioutil.ReadFile("../testdata/" + name)
+		if err != nil {
+			panic(err)
+		}
+
+		programs[name] = string(data)
+	}
+
+	return programs
+}
+
+func parseTree(program string) antlr.ParseTree {
+	input := antlr.NewInputStream(program)
+
+	lexer := parser.NewMonkeyLexer(input)
+	tokens := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
+
+	monkeyParser := parser.NewMonkeyParser(tokens)
+
+	return monkeyParser.Program()
+}
+
+// BenchmarkParsing exercises the full compile pipeline end to end: lexing,
+// parsing, the tree graph visitor, contextual analysis, and native code
+// generation.
+func BenchmarkParsing(b *testing.B) {
+	for name, program := range corpus {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				tree := parseTree(program)
+
+				graph.NewVisitor().Visit(tree)
+
+				contextualErrors := identification.NewErrorsHandler()
+				table := identification.NewTable(contextualErrors)
+				contextual.NewVisitor(table).Visit(tree)
+
+				backend, err := codegenerator.NewBackend(codegenerator.TargetMonkeyVM)
+				if err != nil {
+					b.Fatal(err)
+				}
+				backend.Visit(tree)
+			}
+		})
+	}
+}
+
+// BenchmarkGraphVisitor isolates the tree graph visitor used to render the
+// parse tree for the web playground.
+func BenchmarkGraphVisitor(b *testing.B) {
+	for name, program := range corpus {
+		tree := parseTree(program)
+
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				graph.NewVisitor().Visit(tree)
+			}
+		})
+	}
+}
+
+// BenchmarkContextualVisitor isolates identification-table construction and
+// contextual analysis.
+func BenchmarkContextualVisitor(b *testing.B) {
+	for name, program := range corpus {
+		tree := parseTree(program)
+
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				contextualErrors := identification.NewErrorsHandler()
+				table := identification.NewTable(contextualErrors)
+				contextual.NewVisitor(table).Visit(tree)
+			}
+		})
+	}
+}
+
+// BenchmarkCodeGenerator isolates native bytecode generation
+func BenchmarkCodeGenerator(b *testing.B) {
+	for name, program := range corpus {
+		tree := parseTree(program)
+
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				backend, err := codegenerator.NewBackend(codegenerator.TargetMonkeyVM)
+				if err != nil {
+					b.Fatal(err)
+				}
+				backend.Visit(tree)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+// Command monkey-lsp implements a Language Server Protocol server for the
+// Monkey language, speaking JSON-RPC 2.0 over stdio.
+package main
+
+import (
+	"os"
+
+	"github.com/Mario-Jimenez/gocompiler/lsp"
+)
+
+func main() {
+	if err := lsp.NewServer().Serve(os.Stdin, os.Stdout); err != nil {
+		os.Exit(1)
+	}
+}
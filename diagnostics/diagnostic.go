@@ -0,0 +1,170 @@
+package diagnostics
+
+import "strings"
+
+// Severity classifies how serious a Diagnostic is
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+	Hint
+)
+
+// String renders the severity the way editors expect it on the wire
+func (s Severity) String() string {
+	switch s {
+	case Warning:
+		return "warning"
+	case Hint:
+		return "hint"
+	default:
+		return "error"
+	}
+}
+
+// MarshalJSON renders the severity as its lowercase name instead of its
+// underlying integer value
+func (s Severity) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + s.String() + `"`), nil
+}
+
+// Range spans a region of source text. Lines are 1-based, matching
+// antlr.Token.GetLine(); columns are 0-based and the end column is
+// exclusive, matching antlr.Token.GetColumn() and locateToken's use of
+// strings.Index - the same convention the LSP spec uses for Position, which
+// is what lets lsp.toDiagnostic pass StartCol/EndCol through unchanged and
+// only adjust the line.
+type Range struct {
+	StartLine int `json:"startLine"`
+	StartCol  int `json:"startCol"`
+	EndLine   int `json:"endLine"`
+	EndCol    int `json:"endCol"`
+}
+
+// RelatedInformation points to another location relevant to a Diagnostic,
+// e.g. where a shadowed or unused identifier was originally declared
+type RelatedInformation struct {
+	Message string `json:"message"`
+	Range   Range  `json:"range"`
+}
+
+// Diagnostic is a single compiler finding with enough position information
+// for an editor to underline it
+type Diagnostic struct {
+	Severity            Severity             `json:"severity"`
+	Code                string               `json:"code"`
+	Message             string               `json:"message"`
+	Range               Range                `json:"range"`
+	RelatedInformation  []RelatedInformation `json:"relatedInformation,omitempty"`
+}
+
+// NewDiagnostic builds a single-line Diagnostic, the common case for parser
+// and contextual analysis findings that only know a line number
+func NewDiagnostic(severity Severity, code, message string, line int) Diagnostic {
+	return Diagnostic{
+		Severity: severity,
+		Code:     code,
+		Message:  message,
+		Range: Range{
+			StartLine: line,
+			EndLine:   line,
+		},
+	}
+}
+
+// NewDiagnosticAt builds a single-point Diagnostic for a finding whose exact
+// column is known, e.g. from an antlr.Token, instead of just the line
+// NewDiagnostic assumes.
+func NewDiagnosticAt(severity Severity, code, message string, line, col int) Diagnostic {
+	d := NewDiagnostic(severity, code, message, line)
+	d.Range.StartCol = col
+	d.Range.EndCol = col
+
+	return d
+}
+
+// FromErrors adapts the legacy flat error-string/line-number pairs produced
+// by the parser and contextual analysis listeners into Diagnostics, so
+// callers can serialize a single structured list regardless of which stage
+// produced the finding.
+func FromErrors(severity Severity, code string, messages []string, lines []int) []Diagnostic {
+	result := make([]Diagnostic, len(messages))
+
+	for i, message := range messages {
+		line := 0
+		if i < len(lines) {
+			line = lines[i]
+		}
+
+		result[i] = NewDiagnostic(severity, code, message, line)
+	}
+
+	return result
+}
+
+// FromErrorsIn is FromErrors plus a best-effort column range, derived by
+// locating the offending token named in each message within its source
+// line. The parser and contextual analysis listeners that produce
+// messages/lines only ever report a line, not the column they actually saw,
+// so this is an approximation of the real position rather than the token's
+// exact column.
+func FromErrorsIn(severity Severity, code string, messages []string, lines []int, source string) []Diagnostic {
+	sourceLines := strings.Split(source, "\n")
+	result := FromErrors(severity, code, messages, lines)
+
+	for i, message := range messages {
+		line := 0
+		if i < len(lines) {
+			line = lines[i]
+		}
+
+		col, length, ok := locateToken(message, sourceLines, line)
+		if !ok {
+			continue
+		}
+
+		result[i].Range.StartCol = col
+		result[i].Range.EndCol = col + length
+	}
+
+	return result
+}
+
+// locateToken finds the first '...'-quoted token in message - the shape
+// ANTLR's default error messages use for the offending token, e.g.
+// "mismatched input ';' expecting '}'" - within the given 1-based line of
+// source.
+func locateToken(message string, sourceLines []string, line int) (col, length int, ok bool) {
+	if line < 1 || line > len(sourceLines) {
+		return 0, 0, false
+	}
+
+	token := quotedToken(message)
+	if token == "" {
+		return 0, 0, false
+	}
+
+	idx := strings.Index(sourceLines[line-1], token)
+	if idx < 0 {
+		return 0, 0, false
+	}
+
+	return idx, len(token), true
+}
+
+// quotedToken extracts the first '...'-quoted substring from message, or ""
+// if message does not quote an offending token.
+func quotedToken(message string) string {
+	start := strings.IndexByte(message, '\'')
+	if start < 0 {
+		return ""
+	}
+
+	end := strings.IndexByte(message[start+1:], '\'')
+	if end < 0 {
+		return ""
+	}
+
+	return message[start+1 : start+1+end]
+}
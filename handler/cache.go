@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	"github.com/Mario-Jimenez/gocompiler/diagnostics"
+	"github.com/Mario-Jimenez/gocompiler/identification"
+)
+
+// cacheCapacity bounds how many distinct (program, target) pairs are kept
+// in memory, evicting the least recently used entry once full
+const cacheCapacity = 256
+
+// defaultCompileCache memoizes compile results across requests, keyed by
+// the SHA-256 of the program source and target backend, so repeat compiles
+// of the same source - common in web playground usage - skip the ANTLR
+// pipeline entirely.
+var defaultCompileCache = newCompileCache(cacheCapacity)
+
+// compileResult is what gets memoized for a given source/target pair. table
+// and tree are only set on a successful compile, so a cache hit can restore
+// the session snapshot s.SetAnalysis would have produced on a miss.
+// diagnostics carries the structured findings contextual analysis produced,
+// both non-fatal ones and Error-severity ones, with their own Code and
+// Range intact.
+type compileResult struct {
+	errors      []string
+	lines       []int
+	treeGraph   interface{}
+	code        string
+	extension   string
+	table       *identification.Table
+	tree        interface{}
+	diagnostics []diagnostics.Diagnostic
+}
+
+// compileCache is a fixed-size, in-memory LRU cache of compileResult
+type compileCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+
+	hits   uint64
+	misses uint64
+}
+
+type cacheEntry struct {
+	key    string
+	result compileResult
+}
+
+func newCompileCache(capacity int) *compileCache {
+	return &compileCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// compileCacheKey hashes the program source together with its target
+// backend, so switching targets never serves an artifact for the wrong one
+func compileCacheKey(program, target string) string {
+	sum := sha256.Sum256([]byte(target + "\x00" + program))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *compileCache) get(key string) (compileResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return compileResult{}, false
+	}
+
+	c.order.MoveToFront(elem)
+	c.hits++
+
+	return elem.Value.(*cacheEntry).result, true
+}
+
+func (c *compileCache) set(key string, result compileResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*cacheEntry).result = result
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	elem := c.order.PushFront(&cacheEntry{key: key, result: result})
+	c.entries[key] = elem
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		if oldest != nil {
+			c.order.Remove(oldest)
+			delete(c.entries, oldest.Value.(*cacheEntry).key)
+		}
+	}
+}
+
+// Stats returns the cache's current hit/miss counters
+func (c *compileCache) Stats() (hits, misses uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.hits, c.misses
+}
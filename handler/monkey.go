@@ -5,13 +5,14 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
-	"os/exec"
 	"strings"
+	"time"
 
-	"github.com/Mario-Jimenez/gocompiler/cli"
+	"github.com/Mario-Jimenez/gocompiler/diagnostics"
 	"github.com/Mario-Jimenez/gocompiler/errors"
 	"github.com/Mario-Jimenez/gocompiler/identification"
 	"github.com/Mario-Jimenez/gocompiler/parser"
+	"github.com/Mario-Jimenez/gocompiler/session"
 	"github.com/Mario-Jimenez/gocompiler/visitor/codegenerator"
 	"github.com/Mario-Jimenez/gocompiler/visitor/contextual"
 	"github.com/Mario-Jimenez/gocompiler/visitor/graph"
@@ -19,16 +20,70 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// sessionsDir is where each session's compiled instructions file is stored
+const sessionsDir = "sessions"
+
 // Monkey contains compiler endpoints functions
-type Monkey struct{}
+type Monkey struct {
+	sessions *session.Manager
+}
+
+// NewMonkey creates a Monkey handler backed by a fresh session manager
+func NewMonkey() *Monkey {
+	return &Monkey{
+		sessions: session.NewManager(sessionsDir),
+	}
+}
 
 // Compile request
 type Compile struct {
 	Program string `json:"program"`
+	Target  string `json:"target"`
+}
+
+// CreateSession starts a new compile/run session and returns its id
+func (m *Monkey) CreateSession(c *gin.Context) {
+	s := m.sessions.Create()
+
+	c.JSON(http.StatusOK, gin.H{
+		"id": s.ID,
+	})
+}
+
+// Metrics exposes compile cache hit/miss counters in Prometheus text
+// exposition format
+func (m *Monkey) Metrics(c *gin.Context) {
+	hits, misses := defaultCompileCache.Stats()
+
+	c.String(http.StatusOK,
+		"# HELP monkey_compile_cache_hits_total Total number of compile cache hits.\n"+
+			"# TYPE monkey_compile_cache_hits_total counter\n"+
+			"monkey_compile_cache_hits_total %d\n"+
+			"# HELP monkey_compile_cache_misses_total Total number of compile cache misses.\n"+
+			"# TYPE monkey_compile_cache_misses_total counter\n"+
+			"monkey_compile_cache_misses_total %d\n",
+		hits, misses,
+	)
+}
+
+// DeleteSession ends a session and frees its resources
+func (m *Monkey) DeleteSession(c *gin.Context) {
+	if !m.sessions.Delete(c.Param("id")) {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "Session not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
 }
 
-// Compile incoming program
-func (*Monkey) Compile(c *gin.Context) {
+// Compile incoming program within a session
+func (m *Monkey) Compile(c *gin.Context) {
+	s, ok := m.sessions.Get(c.Param("id"))
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "Session not found"})
+		return
+	}
+
 	// parse incoming request
 	var req Compile
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -38,18 +93,60 @@ func (*Monkey) Compile(c *gin.Context) {
 	}
 
 	// parsing
-	parseErrors, parseLines, parseTree := parsing(req.Program)
+	parseErrors, parseLines, parseTree, structured := parsing(s, req.Program, req.Target)
+
+	response := diagnostics.FromErrorsIn(diagnostics.Error, "compile-error", parseErrors, parseLines, req.Program)
+	response = append(response, structured...)
+
+	// the legacy errors/lines contract predates structured diagnostics, so
+	// fold any Error-severity diagnostic back in - otherwise a program that
+	// fails solely via e.g. a hash-key-mismatch comes back with an empty
+	// errors/lines pair that a caller gating on errors.length == 0 reads as
+	// success, even though no code was generated.
+	errs := append([]string{}, parseErrors...)
+	lines := append([]int{}, parseLines...)
+	for _, diagnostic := range structured {
+		if diagnostic.Severity == diagnostics.Error {
+			errs = append(errs, diagnostic.Message)
+			lines = append(lines, diagnostic.Range.StartLine)
+		}
+	}
 
 	// response
 	c.JSON(200, gin.H{
-		"errors": parseErrors,
-		"lines":  parseLines,
-		"tree":   parseTree,
+		"errors":      errs,
+		"lines":       lines,
+		"tree":        parseTree,
+		"diagnostics": response,
 	})
 }
 
-// parsing program
-func parsing(program string) ([]string, []int, interface{}) {
+// parsing program with the chosen code generation target, recording the
+// resulting identification table and parse tree on the session so later
+// requests (run, future reuse) see a consistent snapshot. The returned
+// diagnostics are the structured findings contextual analysis produced with
+// their own Code and Range intact - both non-fatal ones, e.g. unused
+// declarations, and Error-severity ones, e.g. a hash-key-mismatch, so the
+// caller doesn't have to re-derive the latter from the flattened
+// errors/lines.
+func parsing(s *session.Session, program, target string) ([]string, []int, interface{}, []diagnostics.Diagnostic) {
+	if target == "" {
+		target = codegenerator.TargetMonkeyVM
+	}
+
+	cacheKey := compileCacheKey(program, target)
+	if cached, ok := defaultCompileCache.get(cacheKey); ok {
+		if cached.code != "" {
+			if err := applyArtifact(s, target, cached.extension, cached.code); err != nil {
+				return []string{err.Error()}, []int{}, cached.treeGraph, nil
+			}
+
+			s.SetAnalysis(cached.table.Clone(), cached.tree)
+		}
+
+		return cached.errors, cached.lines, cached.treeGraph, cached.diagnostics
+	}
+
 	// compiler input
 	input := antlr.NewInputStream(program)
 
@@ -83,33 +180,58 @@ func parsing(program string) ([]string, []int, interface{}) {
 		contextualVisitor := contextual.NewVisitor(table)
 		// start of contextual visitor
 		contextualVisitor.Visit(tree)
+		// close the program-level scope, surfacing unused declarations
+		table.CloseScope()
 
-		if contextualErrors.Errors() == nil {
-			// code generator visitor
-			generatorVisitor := codegenerator.NewVisitor()
-			// start of code generator visitor
-			generatorVisitor.Visit(tree)
+		structured := append([]diagnostics.Diagnostic{}, contextualErrors.DiagnosticErrors()...)
+		structured = append(structured, contextualErrors.Warnings()...)
 
-			// save instructions to file
-			err := save([]byte(generatorVisitor.Code()))
+		if !contextualErrors.HasErrors() {
+			// code generator backend for the requested target
+			backend, err := codegenerator.NewBackend(target)
 			if err != nil {
-				return []string{
-					err.Error(),
-				}, []int{}, treeGraph
+				return []string{err.Error()}, []int{}, treeGraph, nil
+			}
+			// start of code generator visitor
+			backend.Visit(tree)
+
+			if err := codegenerator.ErrIfUnsupported(backend); err != nil {
+				return []string{err.Error()}, []int{}, treeGraph, nil
+			}
+
+			code := backend.Code()
+
+			if err := applyArtifact(s, target, backend.Extension(), code); err != nil {
+				return []string{err.Error()}, []int{}, treeGraph, nil
 			}
 
-			return []string{}, []int{}, treeGraph
+			s.SetAnalysis(table, tree)
+
+			defaultCompileCache.set(cacheKey, compileResult{treeGraph: treeGraph, code: code, extension: backend.Extension(), table: table, tree: tree, diagnostics: structured})
+
+			return []string{}, []int{}, treeGraph, structured
 		}
 
-		return contextualErrors.Errors(), contextualErrors.Lines(), treeGraph
+		defaultCompileCache.set(cacheKey, compileResult{errors: contextualErrors.Errors(), lines: contextualErrors.Lines(), treeGraph: treeGraph, diagnostics: structured})
+
+		return contextualErrors.Errors(), contextualErrors.Lines(), treeGraph, structured
 	}
 
-	return parserErrors.Errors(), parserErrors.Lines(), treeGraph
+	defaultCompileCache.set(cacheKey, compileResult{errors: parserErrors.Errors(), lines: parserErrors.Lines(), treeGraph: treeGraph})
+
+	return parserErrors.Errors(), parserErrors.Lines(), treeGraph, nil
+}
+
+// applyArtifact records the backend that produced code and writes it to the
+// session's own instructions file
+func applyArtifact(s *session.Session, target, extension, code string) error {
+	instructionsPath := s.SetArtifact(target, extension)
+	return save(instructionsPath, []byte(code))
 }
 
 // save instructions to file
-func save(data []byte) error {
-	err := ioutil.WriteFile(cli.InstructionsCode, data, os.ModePerm)
+func save(path string, data []byte) error {
+	err := ioutil.WriteFile(path, data, os.ModePerm)
 	if err != nil {
 		return fmt.Errorf("Failed to save file. %s", err.Error())
 	}
@@ -117,10 +239,28 @@ func save(data []byte) error {
 	return nil
 }
 
-// Run program
-func (*Monkey) Run(c *gin.Context) {
+// Run request. TimeoutMs optionally overrides the default wall-clock
+// timeout for this execution; zero (including an absent/empty body) keeps
+// defaultRunTimeout.
+type Run struct {
+	TimeoutMs int `json:"timeoutMs"`
+}
+
+// Run program within a session
+func (m *Monkey) Run(c *gin.Context) {
+	s, ok := m.sessions.Get(c.Param("id"))
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "Session not found"})
+		return
+	}
+
+	// the request body is optional, so a bind error (e.g. no body at all)
+	// just leaves req zeroed rather than aborting the request
+	var req Run
+	_ = c.ShouldBindJSON(&req)
+
 	// execute code on monkey virtual machine
-	result := run()
+	result := run(s, time.Duration(req.TimeoutMs)*time.Millisecond)
 
 	// response
 	c.JSON(200, gin.H{
@@ -128,16 +268,17 @@ func (*Monkey) Run(c *gin.Context) {
 	})
 }
 
-// run monkey virtual machine with instructions code
-func run() string {
-	if _, err := os.Stat(cli.InstructionsCode); err != nil {
-		return fmt.Sprintf("%s\n%s\n...failed", "Instructions code not found. Must compile first.", strings.TrimSpace(err.Error()))
-	}
+// run the session's compiled artifact with the runtime matching the backend
+// it was generated with, bounded by timeout (falling back to
+// defaultRunTimeout, and clamped to maxRunTimeout) so a runaway program
+// can't hang the server
+func run(s *session.Session, timeout time.Duration) string {
+	target, instructionsPath := s.Artifact()
 
-	out, err := exec.Command(cli.VM, cli.InstructionsCode).Output()
-	if err != nil {
-		return fmt.Sprintf("%s\n...failed", strings.TrimSpace(err.Error()))
+	if _, err := os.Stat(instructionsPath); err != nil {
+		return fmt.Sprintf("%s\n%s\n...failed", "Instructions code not found. Must compile first.", strings.TrimSpace(err.Error()))
 	}
 
-	return fmt.Sprintf("%s\n...finished", strings.TrimSpace(string(out)))
+	name, args := vmArgs(target, instructionsPath)
+	return runVM(name, args, timeout)
 }
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// streamUpgrader upgrades the run endpoint to a WebSocket connection. Origin
+// checking is left to the caller's reverse proxy, same as the rest of the API
+var streamUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// streamReadBufferSize is the most bytes read from the VM's stdout/stderr
+// pipes at once; whatever a single read returns is forwarded to the client
+// as one WebSocket message
+const streamReadBufferSize = 4096
+
+// RunStream executes the session's compiled program and streams its
+// stdout/stderr to the client over a WebSocket as it is produced, forwarding
+// incoming client messages to the program's stdin. The subprocess is tied to
+// the connection's lifetime, so a client disconnect kills the VM, and to the
+// same timeoutMs-bounded wall clock Run enforces (defaultRunTimeout, clamped
+// to maxRunTimeout), so a program left blocked on `gets` with the socket
+// held open can't pin the VM subprocess open indefinitely.
+func (m *Monkey) RunStream(c *gin.Context) {
+	s, ok := m.sessions.Get(c.Param("id"))
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "Session not found"})
+		return
+	}
+
+	target, instructionsPath := s.Artifact()
+
+	if _, err := os.Stat(instructionsPath); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "Instructions code not found. Must compile first."})
+		return
+	}
+
+	timeoutMs, _ := strconv.Atoi(c.Query("timeoutMs"))
+	timeout := clampTimeout(time.Duration(timeoutMs) * time.Millisecond)
+
+	conn, err := streamUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	done, stop := deadlineTimer(cancel, timeout)
+	defer stop()
+
+	name, args := vmArgs(target, instructionsPath)
+	cmd := exec.CommandContext(ctx, name, args...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
+		return
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
+		return
+	}
+	cmd.Stderr = cmd.Stdout
+
+	if err := cmd.Start(); err != nil {
+		conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
+		return
+	}
+
+	// forward client messages (e.g. answers to Monkey's `gets`) to the VM's stdin
+	go func() {
+		defer stdin.Close()
+
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			if _, err := stdin.Write(data); err != nil {
+				return
+			}
+		}
+	}()
+
+	// forward the VM's output to the client as it is produced
+	reader := bufio.NewReaderSize(stdout, streamReadBufferSize)
+	buf := make([]byte, streamReadBufferSize)
+	for {
+		n, err := reader.Read(buf)
+		if n > 0 {
+			if writeErr := conn.WriteMessage(websocket.BinaryMessage, buf[:n]); writeErr != nil {
+				cancel()
+				break
+			}
+		}
+
+		if err != nil {
+			if err != io.EOF {
+				conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
+			}
+			break
+		}
+	}
+
+	cmd.Wait()
+
+	reason := "...finished"
+	select {
+	case <-done:
+		reason = "...timed out"
+	default:
+	}
+
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, reason))
+}
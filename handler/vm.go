@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/Mario-Jimenez/gocompiler/cli"
+	"github.com/Mario-Jimenez/gocompiler/visitor/codegenerator"
+)
+
+const (
+	// defaultRunTimeout bounds how long a single VM execution may run
+	// before it is killed, when the caller doesn't request a timeout of
+	// its own
+	defaultRunTimeout = 5 * time.Second
+
+	// maxRunTimeout is the longest wall-clock timeout a caller may request,
+	// so an oversized timeoutMs can't turn into an effectively unbounded run
+	maxRunTimeout = 30 * time.Second
+
+	// runOutputCap bounds how many bytes of VM output are retained, protecting
+	// the server from a runaway program that floods stdout
+	runOutputCap = 64 * 1024
+)
+
+// vmArgs picks the runtime binary and argv matching the backend a session's
+// artifact was compiled with, for both the run and run-stream endpoints.
+// The wasm-text target needs an explicit --invoke: wat.go's module only
+// exports a "main" function, not the WASI _start entry wasmtime's CLI looks
+// for by default when given a bare module path.
+func vmArgs(target, instructionsPath string) (string, []string) {
+	switch target {
+	case codegenerator.TargetJS:
+		return "node", []string{instructionsPath}
+	case codegenerator.TargetWAT:
+		return "wasmtime", []string{"--invoke", "main", instructionsPath}
+	default:
+		return cli.VM, []string{instructionsPath}
+	}
+}
+
+// clampTimeout falls back to defaultRunTimeout if timeout is zero or
+// negative, and clamps it to maxRunTimeout if it's larger, so neither an
+// absent nor an oversized caller-requested timeout can turn into an
+// effectively unbounded run.
+func clampTimeout(timeout time.Duration) time.Duration {
+	if timeout <= 0 {
+		return defaultRunTimeout
+	}
+	if timeout > maxRunTimeout {
+		return maxRunTimeout
+	}
+
+	return timeout
+}
+
+// deadlineTimer arms an absolute deadline against ctx, the same pattern
+// net.Conn deadlines use: a time.AfterFunc closes the returned channel and
+// cancels ctx once timeout elapses, letting the caller tell a timeout apart
+// from any other reason ctx was canceled (e.g. a client disconnect). The
+// returned stop func must be called once ctx is no longer in use.
+func deadlineTimer(cancel context.CancelFunc, timeout time.Duration) (done <-chan struct{}, stop func() bool) {
+	ch := make(chan struct{})
+	timer := time.AfterFunc(timeout, func() {
+		close(ch)
+		cancel()
+	})
+
+	return ch, timer.Stop
+}
+
+// runVM executes name with args under timeout, falling back to
+// defaultRunTimeout if timeout is zero or negative and clamping to
+// maxRunTimeout if it's larger.
+func runVM(name string, args []string, timeout time.Duration) string {
+	timeout = clampTimeout(timeout)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done, stop := deadlineTimer(cancel, timeout)
+	defer stop()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+
+	var out bytes.Buffer
+	capped := &capWriter{buf: &out, limit: runOutputCap}
+	cmd.Stdout = capped
+	cmd.Stderr = capped
+
+	err := cmd.Run()
+
+	select {
+	case <-done:
+		return fmt.Sprintf("%s\n...timed out after %s", strings.TrimSpace(out.String()), timeout)
+	default:
+	}
+
+	if err != nil {
+		return fmt.Sprintf("%s\n...failed", strings.TrimSpace(err.Error()))
+	}
+
+	return fmt.Sprintf("%s\n...finished", strings.TrimSpace(out.String()))
+}
+
+// capWriter limits how many bytes are retained from a stream, discarding the
+// remainder so a runaway program cannot exhaust server memory
+type capWriter struct {
+	buf   *bytes.Buffer
+	limit int
+}
+
+func (w *capWriter) Write(p []byte) (int, error) {
+	if remaining := w.limit - w.buf.Len(); remaining > 0 {
+		if remaining < len(p) {
+			w.buf.Write(p[:remaining])
+		} else {
+			w.buf.Write(p)
+		}
+	}
+
+	// always report the full length written so exec does not treat this
+	// as a broken pipe
+	return len(p), nil
+}
@@ -0,0 +1,59 @@
+package identification
+
+import (
+	"fmt"
+
+	"github.com/Mario-Jimenez/gocompiler/diagnostics"
+)
+
+// ArrayElementType records which Monkey value type an array literal's
+// elements were declared with, persisted on an ARRAY attribute's data so
+// later reads and assignments can be checked for consistency.
+type ArrayElementType int
+
+const (
+	ArrayElementUnknown ArrayElementType = iota
+	ArrayElementInteger
+	ArrayElementString
+	ArrayElementMixed
+)
+
+// String renders the element type the way it reads in a diagnostic message
+func (t ArrayElementType) String() string {
+	switch t {
+	case ArrayElementInteger:
+		return "integer"
+	case ArrayElementString:
+		return "string"
+	case ArrayElementMixed:
+		return "mixed"
+	default:
+		return "unknown"
+	}
+}
+
+// ArrayData is the persisted data for an ARRAY-typed attribute
+type ArrayData struct {
+	ElementType ArrayElementType
+}
+
+// CheckElement reports a diagnostic if elementType is incompatible with the
+// array's recorded element type, e.g. assigning a string into an
+// integer-typed array - the array equivalent of HashData.CheckKey, and
+// deliberately kept stateless the same way: it never mutates a.ElementType,
+// so a real mismatch is reported every time it recurs instead of only once.
+// Unifying repeated elements of an array literal into a single recorded
+// ElementType happens earlier, in arrayHelper, before an *ArrayData is ever
+// persisted here. An array declared with mixed or unknown elements has
+// nothing left to enforce, and neither does an element whose own type
+// couldn't be statically determined (e.g. a computed expression like
+// `i - 1`) - better to miss a real mismatch than reject a correct program.
+func (a *ArrayData) CheckElement(elementType ArrayElementType, line int) (diagnostics.Diagnostic, bool) {
+	if elementType == ArrayElementUnknown || a.ElementType == ArrayElementMixed || a.ElementType == ArrayElementUnknown || elementType == a.ElementType {
+		return diagnostics.Diagnostic{}, false
+	}
+
+	message := fmt.Sprintf("array declared with %s elements, mixed with %s at line %d", a.ElementType, elementType, line)
+
+	return diagnostics.NewDiagnostic(diagnostics.Error, "array-element-mismatch", message, line), true
+}
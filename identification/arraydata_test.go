@@ -0,0 +1,54 @@
+package identification
+
+import (
+	"testing"
+
+	"github.com/Mario-Jimenez/gocompiler/diagnostics"
+)
+
+func TestArrayDataCheckElement(t *testing.T) {
+	tests := []struct {
+		name         string
+		declared     ArrayElementType
+		elementType  ArrayElementType
+		wantOk       bool
+		wantSeverity diagnostics.Severity
+	}{
+		{name: "matching element type", declared: ArrayElementInteger, elementType: ArrayElementInteger, wantOk: false},
+		{name: "mismatched element type", declared: ArrayElementInteger, elementType: ArrayElementString, wantOk: true, wantSeverity: diagnostics.Error},
+		{name: "computed element of unknown type is not evidence of a mismatch", declared: ArrayElementInteger, elementType: ArrayElementUnknown, wantOk: false},
+		{name: "array declared with unknown elements has nothing left to enforce", declared: ArrayElementUnknown, elementType: ArrayElementString, wantOk: false},
+		{name: "array already unified as mixed has nothing left to enforce", declared: ArrayElementMixed, elementType: ArrayElementString, wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &ArrayData{ElementType: tt.declared}
+
+			diagnostic, ok := a.CheckElement(tt.elementType, 1)
+			if ok != tt.wantOk {
+				t.Fatalf("CheckElement() ok = %v, want %v", ok, tt.wantOk)
+			}
+
+			if tt.wantOk && diagnostic.Severity != tt.wantSeverity {
+				t.Errorf("CheckElement() severity = %v, want %v", diagnostic.Severity, tt.wantSeverity)
+			}
+		})
+	}
+
+	t.Run("CheckElement never mutates ElementType, unlike unifying an array literal's elements", func(t *testing.T) {
+		a := &ArrayData{ElementType: ArrayElementInteger}
+
+		if _, ok := a.CheckElement(ArrayElementString, 1); !ok {
+			t.Fatalf("CheckElement() did not report the mismatch")
+		}
+
+		if a.ElementType != ArrayElementInteger {
+			t.Errorf("ElementType = %v, want unchanged %v - CheckElement must stay stateless so a repeated mismatch is reported every time, the same way HashData.CheckKey does", a.ElementType, ArrayElementInteger)
+		}
+
+		if _, ok := a.CheckElement(ArrayElementString, 2); !ok {
+			t.Errorf("CheckElement() did not report the same mismatch again on a second occurrence")
+		}
+	})
+}
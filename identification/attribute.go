@@ -1,6 +1,9 @@
 package identification
 
 import (
+	"fmt"
+
+	"github.com/Mario-Jimenez/gocompiler/diagnostics"
 	"github.com/antlr/antlr4/runtime/Go/antlr"
 )
 
@@ -15,6 +18,18 @@ const (
 	ARRAY
 )
 
+// LiteralType is the syntactic shape of the expression an attribute was
+// declared with, as far as it can be told without real type inference -
+// persisted so a later reference to the identifier (e.g. used as a hash/array
+// index key) classifies the same way the literal it resolves to would.
+type LiteralType int
+
+const (
+	LiteralUnknown LiteralType = iota
+	LiteralInteger
+	LiteralString
+)
+
 /*
 	indentification table attribute
 	data stores information for a especific type of expression
@@ -23,19 +38,22 @@ const (
 		- hash (hashdata.go)
 		- array (arraydata.go)
 */
+
 type attribute struct {
 	expression ExpressionType
 	token      antlr.Token
 	visited    bool
 	data       interface{}
+	literal    LiteralType
 }
 
 // constructor
-func NewAttribute(expression ExpressionType, token antlr.Token, data interface{}) *attribute {
+func NewAttribute(expression ExpressionType, token antlr.Token, data interface{}, literal LiteralType) *attribute {
 	return &attribute{
 		expression: expression,
 		token:      token,
 		data:       data,
+		literal:    literal,
 	}
 }
 
@@ -44,6 +62,25 @@ func (a *attribute) getToken() antlr.Token {
 	return a.token
 }
 
+// Complete backfills a with the expression/data/literal a self-referencing
+// declaration couldn't know until its value was fully walked, e.g. a
+// recursive function whose own body looks itself up while still being
+// visited. a is declared with a placeholder up front so that lookup
+// succeeds, then completed in place once its value is known - the table
+// keeps pointing at the same attribute throughout, the same binding a
+// runtime closure would resolve to.
+func (a *attribute) Complete(expression ExpressionType, data interface{}, literal LiteralType) {
+	a.expression = expression
+	a.data = data
+	a.literal = literal
+}
+
+// GetToken returns the token a was declared at, e.g. for a caller outside
+// this package that wants to point an editor at the declaration site
+func (a *attribute) GetToken() antlr.Token {
+	return a.token
+}
+
 // a declaration was used
 func (a *attribute) markVisited() {
 	a.visited = true
@@ -54,6 +91,19 @@ func (a *attribute) wasVisited() bool {
 	return a.visited
 }
 
+// UnusedDiagnostic reports a as an unused declaration, if it qualifies.
+// It returns false when a was visited, so the caller (the identification
+// table, when closing a scope) has nothing to report.
+func (a *attribute) UnusedDiagnostic() (diagnostics.Diagnostic, bool) {
+	if a.wasVisited() {
+		return diagnostics.Diagnostic{}, false
+	}
+
+	message := fmt.Sprintf("%q is declared but never used", a.token.GetText())
+
+	return diagnostics.NewDiagnosticAt(diagnostics.Warning, "unused-declaration", message, a.token.GetLine(), a.token.GetColumn()), true
+}
+
 // getter
 func (a *attribute) GetType() ExpressionType {
 	return a.expression
@@ -63,3 +113,35 @@ func (a *attribute) GetType() ExpressionType {
 func (a *attribute) GetData() interface{} {
 	return a.data
 }
+
+// GetLiteral returns the syntactic shape a was declared with, so a
+// reference to the identifier can be checked as if it were written as that
+// literal itself, e.g. indexing a hash with a variable key.
+func (a *attribute) GetLiteral() LiteralType {
+	return a.literal
+}
+
+// CheckHashKey reports a diagnostic if a is a HASH attribute whose recorded
+// key type is incompatible with keyType, e.g. a `h[k]` index-assignment or
+// read using the wrong kind of key. It returns false for anything other
+// than a HASH attribute.
+func (a *attribute) CheckHashKey(keyType HashKeyType, line int) (diagnostics.Diagnostic, bool) {
+	data, ok := a.data.(*HashData)
+	if !ok {
+		return diagnostics.Diagnostic{}, false
+	}
+
+	return data.CheckKey(keyType, line)
+}
+
+// CheckArrayElement reports a diagnostic if a is an ARRAY attribute whose
+// recorded element type is incompatible with elementType. It returns false
+// for anything other than an ARRAY attribute.
+func (a *attribute) CheckArrayElement(elementType ArrayElementType, line int) (diagnostics.Diagnostic, bool) {
+	data, ok := a.data.(*ArrayData)
+	if !ok {
+		return diagnostics.Diagnostic{}, false
+	}
+
+	return data.CheckElement(elementType, line)
+}
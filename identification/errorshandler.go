@@ -0,0 +1,88 @@
+package identification
+
+import "github.com/Mario-Jimenez/gocompiler/diagnostics"
+
+// ErrorsHandler collects contextual-analysis errors as the table is built,
+// in the same flat message/line shape errors.ParserErrorListener uses for
+// parse errors, so both flow through diagnostics.FromErrorsIn uniformly.
+// Unused-declaration findings and Error-severity structured diagnostics
+// (e.g. a hash/array key-type mismatch) are collected separately since they
+// already carry their own exact position, code and severity.
+type ErrorsHandler struct {
+	errors           []string
+	lines            []int
+	warnings         []diagnostics.Diagnostic
+	diagnosticErrors []diagnostics.Diagnostic
+}
+
+// NewErrorsHandler creates an empty contextual-analysis error collector
+func NewErrorsHandler() *ErrorsHandler {
+	return &ErrorsHandler{}
+}
+
+// addError records a contextual-analysis error, e.g. an undeclared name or
+// redeclaration, at line
+func (e *ErrorsHandler) addError(message string, line int) {
+	e.errors = append(e.errors, message)
+	e.lines = append(e.lines, line)
+}
+
+// addDiagnostic records a finding that already carries its own Diagnostic,
+// e.g. an unused declaration or a hash/array key-type mismatch, routing it
+// by its own severity: Error is kept in DiagnosticErrors with its Code and
+// Range intact, so HasErrors still gates "did contextual analysis succeed"
+// without flattening it through Errors/Lines; anything else is kept as a
+// structured Diagnostic in Warnings.
+func (e *ErrorsHandler) addDiagnostic(diagnostic diagnostics.Diagnostic) {
+	if diagnostic.Severity == diagnostics.Error {
+		e.diagnosticErrors = append(e.diagnosticErrors, diagnostic)
+		return
+	}
+
+	e.warnings = append(e.warnings, diagnostic)
+}
+
+// Errors returns the flat contextual-analysis error messages added via
+// addError, e.g. an undeclared name or redeclaration. Error-severity
+// structured diagnostics added via addDiagnostic are not included here -
+// see DiagnosticErrors - so a caller that wants their precise Code and
+// Range doesn't have to re-derive it from a flattened string.
+func (e *ErrorsHandler) Errors() []string {
+	return e.errors
+}
+
+// Lines returns the line number each message in Errors was reported at
+func (e *ErrorsHandler) Lines() []int {
+	return e.lines
+}
+
+// DiagnosticErrors returns the Error-severity diagnostics collected via
+// addDiagnostic, e.g. a hash-key-mismatch, with their own Code and Range
+// intact.
+func (e *ErrorsHandler) DiagnosticErrors() []diagnostics.Diagnostic {
+	return e.diagnosticErrors
+}
+
+// HasErrors reports whether any error-level finding was collected, whether
+// a flat addError message or a structured Error-severity Diagnostic - the
+// gate callers use to decide whether contextual analysis succeeded.
+func (e *ErrorsHandler) HasErrors() bool {
+	return len(e.errors) > 0 || len(e.diagnosticErrors) > 0
+}
+
+// Warnings returns the non-fatal diagnostics collected so far, e.g. unused
+// declarations surfaced when a scope closes
+func (e *ErrorsHandler) Warnings() []diagnostics.Diagnostic {
+	return e.warnings
+}
+
+// clone returns a copy of e with its own backing slices, so appending to the
+// copy (e.g. via a cloned Table's Report) never bleeds into e's.
+func (e *ErrorsHandler) clone() *ErrorsHandler {
+	return &ErrorsHandler{
+		errors:           append([]string{}, e.errors...),
+		lines:            append([]int{}, e.lines...),
+		warnings:         append([]diagnostics.Diagnostic{}, e.warnings...),
+		diagnosticErrors: append([]diagnostics.Diagnostic{}, e.diagnosticErrors...),
+	}
+}
@@ -0,0 +1,55 @@
+package identification
+
+import (
+	"fmt"
+
+	"github.com/Mario-Jimenez/gocompiler/diagnostics"
+)
+
+// HashKeyType records which Monkey value type was used for a hash literal's
+// keys, persisted on a HASH attribute's data so later index expressions can
+// be checked against it instead of the check being thrown away once the
+// literal finishes traversing.
+type HashKeyType int
+
+const (
+	HashKeyUnknown HashKeyType = iota
+	HashKeyInteger
+	HashKeyString
+	HashKeyMixed
+)
+
+// String renders the key type the way it reads in a diagnostic message
+func (t HashKeyType) String() string {
+	switch t {
+	case HashKeyInteger:
+		return "integer"
+	case HashKeyString:
+		return "string"
+	case HashKeyMixed:
+		return "mixed"
+	default:
+		return "unknown"
+	}
+}
+
+// HashData is the persisted data for a HASH-typed attribute
+type HashData struct {
+	KeyType HashKeyType
+}
+
+// CheckKey reports a diagnostic if keyType is incompatible with the hash's
+// recorded key type, e.g. indexing a string-keyed hash with an integer. A
+// hash declared with mixed or unknown keys has nothing left to enforce, and
+// neither does a key whose own type couldn't be statically determined (e.g.
+// a computed expression like `i + 1`) - better to miss a real mismatch than
+// reject a correct program.
+func (h *HashData) CheckKey(keyType HashKeyType, line int) (diagnostics.Diagnostic, bool) {
+	if keyType == HashKeyUnknown || h.KeyType == HashKeyMixed || h.KeyType == HashKeyUnknown || keyType == h.KeyType {
+		return diagnostics.Diagnostic{}, false
+	}
+
+	message := fmt.Sprintf("hash declared with %s keys, indexed with %s at line %d", h.KeyType, keyType, line)
+
+	return diagnostics.NewDiagnostic(diagnostics.Error, "hash-key-mismatch", message, line), true
+}
@@ -0,0 +1,38 @@
+package identification
+
+import (
+	"testing"
+
+	"github.com/Mario-Jimenez/gocompiler/diagnostics"
+)
+
+func TestHashDataCheckKey(t *testing.T) {
+	tests := []struct {
+		name         string
+		declared     HashKeyType
+		keyType      HashKeyType
+		wantOk       bool
+		wantSeverity diagnostics.Severity
+	}{
+		{name: "matching key type", declared: HashKeyString, keyType: HashKeyString, wantOk: false},
+		{name: "mismatched key type", declared: HashKeyString, keyType: HashKeyInteger, wantOk: true, wantSeverity: diagnostics.Error},
+		{name: "computed key of unknown type is not evidence of a mismatch", declared: HashKeyString, keyType: HashKeyUnknown, wantOk: false},
+		{name: "hash declared with mixed keys has nothing left to enforce", declared: HashKeyMixed, keyType: HashKeyInteger, wantOk: false},
+		{name: "hash declared with unknown keys has nothing left to enforce", declared: HashKeyUnknown, keyType: HashKeyInteger, wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HashData{KeyType: tt.declared}
+
+			diagnostic, ok := h.CheckKey(tt.keyType, 1)
+			if ok != tt.wantOk {
+				t.Fatalf("CheckKey() ok = %v, want %v", ok, tt.wantOk)
+			}
+
+			if tt.wantOk && diagnostic.Severity != tt.wantSeverity {
+				t.Errorf("CheckKey() severity = %v, want %v", diagnostic.Severity, tt.wantSeverity)
+			}
+		})
+	}
+}
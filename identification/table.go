@@ -0,0 +1,164 @@
+package identification
+
+import (
+	"fmt"
+
+	"github.com/Mario-Jimenez/gocompiler/diagnostics"
+)
+
+// scope is a single lexical level of the table: one is pushed for the
+// program itself and another for each function body and if/else branch the
+// contextual visitor descends into, so a declaration can't leak into its
+// enclosing scope.
+type scope struct {
+	attributes map[string]*attribute
+	order      []string
+}
+
+func newScope() *scope {
+	return &scope{attributes: make(map[string]*attribute)}
+}
+
+// Table tracks declared attributes across nested lexical scopes as the
+// contextual visitor walks the tree, reporting redeclarations, undeclared
+// names and unused declarations through an ErrorsHandler.
+type Table struct {
+	scopes []*scope
+	errors *ErrorsHandler
+	all    map[string]*attribute
+}
+
+// NewTable creates a table rooted in a single program-level scope, reporting
+// through errors
+func NewTable(errors *ErrorsHandler) *Table {
+	table := &Table{errors: errors, all: make(map[string]*attribute)}
+	table.OpenScope()
+
+	return table
+}
+
+// OpenScope pushes a fresh lexical scope, e.g. on entering a function body
+func (t *Table) OpenScope() {
+	t.scopes = append(t.scopes, newScope())
+}
+
+// CloseScope pops the innermost scope, reporting every declaration in it
+// that was never looked up via Lookup as an unused-declaration warning.
+func (t *Table) CloseScope() {
+	current := t.scopes[len(t.scopes)-1]
+	t.scopes = t.scopes[:len(t.scopes)-1]
+
+	for _, name := range current.order {
+		if diagnostic, ok := current.attributes[name].UnusedDiagnostic(); ok {
+			t.errors.addDiagnostic(diagnostic)
+		}
+	}
+}
+
+// Report records a diagnostic produced while checking an attribute, e.g.
+// attribute.CheckHashKey/CheckArrayElement on an index expression, through
+// this table's ErrorsHandler the same way CloseScope does for an unused
+// declaration.
+func (t *Table) Report(diagnostic diagnostics.Diagnostic) {
+	t.errors.addDiagnostic(diagnostic)
+}
+
+// Declare adds attr to the innermost scope under name. It reports a
+// redeclaration error and returns false if name is already declared at this
+// level - shadowing an outer scope's declaration is allowed.
+func (t *Table) Declare(name string, attr *attribute) bool {
+	current := t.scopes[len(t.scopes)-1]
+
+	if _, exists := current.attributes[name]; exists {
+		t.errors.addError(fmt.Sprintf("%q is already declared in this scope", name), attr.getToken().GetLine())
+		return false
+	}
+
+	current.attributes[name] = attr
+	current.order = append(current.order, name)
+	t.all[name] = attr
+
+	return true
+}
+
+// Find looks up name across every scope ever declared in this table,
+// without marking it visited or requiring the scope it was declared in to
+// still be open. It's a lighter query than Lookup for read-only consumers
+// like the LSP that describe a name after analysis has already finished and
+// every scope has closed - at the cost of not respecting shadowing: it
+// returns whichever declaration of name was seen last, not the one actually
+// in scope at a given position.
+func (t *Table) Find(name string) (*attribute, bool) {
+	attr, ok := t.all[name]
+	return attr, ok
+}
+
+// Lookup resolves name from the innermost scope outward, marking it visited
+// so CloseScope won't flag it as unused. It reports an undeclared-name error
+// and returns false if no enclosing scope declares it.
+func (t *Table) Lookup(name string, line int) (*attribute, bool) {
+	for i := len(t.scopes) - 1; i >= 0; i-- {
+		if attr, ok := t.scopes[i].attributes[name]; ok {
+			attr.markVisited()
+			return attr, true
+		}
+	}
+
+	t.errors.addError(fmt.Sprintf("%q is not declared", name), line)
+
+	return nil, false
+}
+
+// Clone returns a deep copy of t, safe to hand to a session independently
+// of whatever produced it. The compile cache memoizes one *Table per unique
+// program/target pair and restores it on every cache hit, so without this
+// two sessions that happen to compile the same source would share the same
+// attribute instances and the same *ErrorsHandler - mutating a.visited or
+// a.data (a *HashData/*ArrayData pointer) for one session's lookups, or
+// reporting through errors, would bleed into the other's.
+func (t *Table) Clone() *Table {
+	clone := &Table{errors: t.errors.clone(), all: make(map[string]*attribute, len(t.all))}
+
+	cloned := make(map[*attribute]*attribute, len(t.all))
+	cloneAttr := func(a *attribute) *attribute {
+		if c, ok := cloned[a]; ok {
+			return c
+		}
+
+		c := &attribute{expression: a.expression, token: a.token, visited: a.visited, data: cloneData(a.data), literal: a.literal}
+		cloned[a] = c
+
+		return c
+	}
+
+	for _, s := range t.scopes {
+		ns := newScope()
+		for _, name := range s.order {
+			ns.order = append(ns.order, name)
+			ns.attributes[name] = cloneAttr(s.attributes[name])
+		}
+		clone.scopes = append(clone.scopes, ns)
+	}
+
+	for name, a := range t.all {
+		clone.all[name] = cloneAttr(a)
+	}
+
+	return clone
+}
+
+// cloneData copies the mutable data an attribute's HASH/ARRAY entry points
+// at, so a clone's CheckHashKey/CheckArrayElement never mutates the
+// original table's HashData/ArrayData.
+func cloneData(data interface{}) interface{} {
+	switch d := data.(type) {
+	case *HashData:
+		clone := *d
+		return &clone
+	case *ArrayData:
+		clone := *d
+		return &clone
+	default:
+		return data
+	}
+}
@@ -0,0 +1,85 @@
+package lsp
+
+import (
+	"github.com/Mario-Jimenez/gocompiler/diagnostics"
+	"github.com/Mario-Jimenez/gocompiler/errors"
+	"github.com/Mario-Jimenez/gocompiler/identification"
+	"github.com/Mario-Jimenez/gocompiler/parser"
+	"github.com/Mario-Jimenez/gocompiler/visitor/contextual"
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// analysis is the result of running the parser and contextual analysis
+// passes over a buffer, entirely in memory
+type analysis struct {
+	tree        antlr.ParseTree
+	tokens      *antlr.CommonTokenStream
+	table       *identification.Table
+	diagnostics []diagnostics.Diagnostic
+}
+
+// analyze parses and contextually checks text the same way handler.parsing
+// does for the web API, but never touches disk: an LSP client edits buffers
+// far more often than a web playground compiles, so every byte written here
+// is wasted latency on the next keystroke.
+func analyze(text string) analysis {
+	input := antlr.NewInputStream(text)
+
+	parserErrors := errors.NewParserErrorListener()
+
+	lexer := parser.NewMonkeyLexer(input)
+	lexer.RemoveErrorListeners()
+	lexer.AddErrorListener(parserErrors)
+
+	tokens := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
+
+	monkeyParser := parser.NewMonkeyParser(tokens)
+	monkeyParser.RemoveErrorListeners()
+	monkeyParser.AddErrorListener(parserErrors)
+
+	tree := monkeyParser.Program()
+
+	if parserErrors.Errors() != nil {
+		return analysis{
+			tree:        tree,
+			tokens:      tokens,
+			diagnostics: diagnostics.FromErrorsIn(diagnostics.Error, "parse-error", parserErrors.Errors(), parserErrors.Lines(), text),
+		}
+	}
+
+	contextualErrors := identification.NewErrorsHandler()
+	table := identification.NewTable(contextualErrors)
+	contextual.NewVisitor(table).Visit(tree)
+	// close the program-level scope, surfacing unused declarations
+	table.CloseScope()
+
+	// structured carries every contextual-analysis finding with its own
+	// Code and Range intact, Error-severity ones included - see
+	// handler.parsing, which merges them the same way for the HTTP API.
+	structured := append([]diagnostics.Diagnostic{}, contextualErrors.DiagnosticErrors()...)
+	structured = append(structured, contextualErrors.Warnings()...)
+
+	if contextualErrors.Errors() != nil {
+		structured = append(structured, diagnostics.FromErrorsIn(diagnostics.Error, "context-error", contextualErrors.Errors(), contextualErrors.Lines(), text)...)
+	}
+
+	return analysis{
+		tree:        tree,
+		tokens:      tokens,
+		table:       table,
+		diagnostics: structured,
+	}
+}
+
+// ok reports whether the buffer analyzed cleanly, i.e. has a tree that
+// hover/definition can trust. Warnings such as unused declarations don't
+// disqualify it - only a parse or context error does.
+func (a analysis) ok() bool {
+	for _, diagnostic := range a.diagnostics {
+		if diagnostic.Severity == diagnostics.Error {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,109 @@
+package lsp
+
+import (
+	"sync"
+	"time"
+)
+
+// debounceDelay is how long didChange waits for further edits before
+// re-running the parser/contextual pipeline, so a fast typist doesn't pay
+// for a full reanalysis on every keystroke
+const debounceDelay = 200 * time.Millisecond
+
+// document tracks one open buffer: its current text plus the last analysis
+// that came back clean, so hover/definition keep working while the buffer
+// has a parse error mid-edit.
+type document struct {
+	mu sync.Mutex
+
+	uri  string
+	text string
+
+	timer *time.Timer
+
+	current  analysis
+	lastGood analysis
+}
+
+// store holds every open document, keyed by URI
+type store struct {
+	mu   sync.Mutex
+	docs map[string]*document
+}
+
+func newStore() *store {
+	return &store{
+		docs: make(map[string]*document),
+	}
+}
+
+// open creates or replaces a document and analyzes it immediately, without
+// debouncing: didOpen is a one-shot event, not a stream of keystrokes
+func (s *store) open(uri, text string) *document {
+	d := &document{uri: uri, text: text}
+	d.apply(analyze(text))
+
+	s.mu.Lock()
+	s.docs[uri] = d
+	s.mu.Unlock()
+
+	return d
+}
+
+// get returns the document for uri, or nil if it is not open
+func (s *store) get(uri string) *document {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.docs[uri]
+}
+
+// close drops a document from the store
+func (s *store) close(uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.docs, uri)
+}
+
+// apply records a fresh analysis result, keeping the previous good tree
+// around if this one has errors
+func (d *document) apply(a analysis) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.current = a
+	if a.ok() {
+		d.lastGood = a
+	}
+}
+
+// change updates the buffer text and schedules a debounced reanalysis,
+// invoking onDone (typically publishDiagnostics) once it completes
+func (d *document) change(text string, onDone func(analysis)) {
+	d.mu.Lock()
+	d.text = text
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.timer = time.AfterFunc(debounceDelay, func() {
+		a := analyze(text)
+		d.apply(a)
+		onDone(a)
+	})
+	d.mu.Unlock()
+}
+
+// analysisForReading returns the most useful analysis for a read-only
+// request (hover, definition, completion): the current one if it analyzed
+// cleanly (no parse or context error), otherwise the last one that did.
+func (d *document) analysisForReading() analysis {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.current.ok() {
+		return d.current
+	}
+
+	return d.lastGood
+}
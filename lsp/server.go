@@ -0,0 +1,548 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/Mario-Jimenez/gocompiler/identification"
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// Server speaks JSON-RPC 2.0 over stdio and answers a subset of the
+// Language Server Protocol for Monkey buffers, reusing the same
+// parser/identification/contextual pipeline the web API uses, entirely
+// in memory.
+type Server struct {
+	docs *store
+	out  io.Writer
+
+	// outMu serializes writes to out: a didChange debounce timer publishes
+	// diagnostics from its own goroutine, concurrently with Serve's request
+	// loop replying to whatever else the client sent in the meantime, and
+	// writeMessage's header-then-body writes would otherwise interleave and
+	// corrupt the Content-Length framing.
+	outMu sync.Mutex
+}
+
+// NewServer creates an LSP server with no open documents; Serve then reads
+// requests from in and writes responses/notifications to out
+func NewServer() *Server {
+	return &Server{
+		docs: newStore(),
+	}
+}
+
+// Serve runs the JSON-RPC read loop until in is closed
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.out = out
+	reader := bufio.NewReader(in)
+
+	for {
+		body, err := readMessage(reader)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.reply(nil, nil, &rpcError{Code: errParseError, Message: err.Error()})
+			continue
+		}
+
+		s.dispatch(req)
+	}
+}
+
+func (s *Server) dispatch(req request) {
+	switch req.Method {
+	case "initialize":
+		s.reply(req.ID, M{
+			"capabilities": M{
+				"textDocumentSync":       1,
+				"hoverProvider":          true,
+				"definitionProvider":     true,
+				"completionProvider":     M{"triggerCharacters": []string{"."}},
+				"semanticTokensProvider": M{"legend": M{"tokenTypes": semanticTokenTypes}, "full": true},
+			},
+		}, nil)
+	case "initialized", "$/cancelRequest":
+		// no-op notifications
+	case "textDocument/didOpen":
+		s.handleDidOpen(req)
+	case "textDocument/didChange":
+		s.handleDidChange(req)
+	case "textDocument/didClose":
+		s.handleDidClose(req)
+	case "textDocument/hover":
+		s.handleHover(req)
+	case "textDocument/definition":
+		s.handleDefinition(req)
+	case "textDocument/completion":
+		s.handleCompletion(req)
+	case "textDocument/semanticTokens/full":
+		s.handleSemanticTokens(req)
+	case "shutdown":
+		s.reply(req.ID, nil, nil)
+	case "exit":
+		// handled by the caller closing the transport
+	default:
+		if len(req.ID) > 0 {
+			s.reply(req.ID, nil, &rpcError{Code: errMethodNotFound, Message: "method not found: " + req.Method})
+		}
+	}
+}
+
+func (s *Server) reply(id json.RawMessage, result interface{}, rpcErr *rpcError) {
+	s.outMu.Lock()
+	defer s.outMu.Unlock()
+
+	writeMessage(s.out, response{JSONRPC: "2.0", ID: id, Result: result, Error: rpcErr})
+}
+
+func (s *Server) notify(method string, params interface{}) {
+	s.outMu.Lock()
+	defer s.outMu.Unlock()
+
+	writeMessage(s.out, notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+type textDocumentItem struct {
+	URI  string `json:"uri"`
+	Text string `json:"text"`
+}
+
+type contentChange struct {
+	Text string `json:"text"`
+}
+
+type didOpenParams struct {
+	TextDocument textDocumentItem `json:"textDocument"`
+}
+
+type didChangeParams struct {
+	TextDocument struct {
+		URI string `json:"uri"`
+	} `json:"textDocument"`
+	ContentChanges []contentChange `json:"contentChanges"`
+}
+
+type didCloseParams struct {
+	TextDocument struct {
+		URI string `json:"uri"`
+	} `json:"textDocument"`
+}
+
+type positionParams struct {
+	TextDocument struct {
+		URI string `json:"uri"`
+	} `json:"textDocument"`
+	Position Position `json:"position"`
+}
+
+func (s *Server) handleDidOpen(req request) {
+	var params didOpenParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return
+	}
+
+	doc := s.docs.open(params.TextDocument.URI, params.TextDocument.Text)
+	s.publishDiagnostics(params.TextDocument.URI, doc.current)
+}
+
+func (s *Server) handleDidChange(req request) {
+	var params didChangeParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return
+	}
+
+	doc := s.docs.get(params.TextDocument.URI)
+	if doc == nil || len(params.ContentChanges) == 0 {
+		return
+	}
+
+	// full-document sync: the last content change carries the whole buffer
+	text := params.ContentChanges[len(params.ContentChanges)-1].Text
+	uri := params.TextDocument.URI
+
+	doc.change(text, func(a analysis) {
+		s.publishDiagnostics(uri, a)
+	})
+}
+
+func (s *Server) handleDidClose(req request) {
+	var params didCloseParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return
+	}
+
+	s.docs.close(params.TextDocument.URI)
+}
+
+func (s *Server) publishDiagnostics(uri string, a analysis) {
+	diags := make([]Diagnostic, len(a.diagnostics))
+	for i, d := range a.diagnostics {
+		diags[i] = toDiagnostic(d)
+	}
+
+	s.notify("textDocument/publishDiagnostics", M{
+		"uri":         uri,
+		"diagnostics": diags,
+	})
+}
+
+func (s *Server) handleHover(req request) {
+	var params positionParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		s.reply(req.ID, nil, nil)
+		return
+	}
+
+	doc := s.docs.get(params.TextDocument.URI)
+	if doc == nil {
+		s.reply(req.ID, nil, nil)
+		return
+	}
+
+	a := doc.analysisForReading()
+	tok := tokenAt(a, params.Position)
+	if tok == nil {
+		s.reply(req.ID, nil, nil)
+		return
+	}
+
+	s.reply(req.ID, Hover{Contents: describeToken(a, tok)}, nil)
+}
+
+func (s *Server) handleDefinition(req request) {
+	var params positionParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		s.reply(req.ID, nil, nil)
+		return
+	}
+
+	doc := s.docs.get(params.TextDocument.URI)
+	if doc == nil {
+		s.reply(req.ID, nil, nil)
+		return
+	}
+
+	a := doc.analysisForReading()
+	tok := tokenAt(a, params.Position)
+	if tok == nil || !isIdentifier(tok.GetText()) {
+		s.reply(req.ID, nil, nil)
+		return
+	}
+
+	candidate := resolveDeclaration(a, tok.GetText(), params.Position)
+	if candidate == nil {
+		s.reply(req.ID, nil, nil)
+		return
+	}
+
+	s.reply(req.ID, Location{
+		URI: params.TextDocument.URI,
+		Range: Range{
+			Start: Position{Line: candidate.GetLine() - 1, Character: candidate.GetColumn()},
+			End:   Position{Line: candidate.GetLine() - 1, Character: candidate.GetColumn() + len(candidate.GetText())},
+		},
+	}, nil)
+}
+
+// resolveDeclaration returns the token where text was declared, preferring
+// a.table's own record of it - the table doesn't resolve shadowing by
+// position either, but it is at least the real declaration the contextual
+// visitor attached to this name, not a guess. It falls back to the lexical
+// declarationBefore heuristic when the table has nothing for text, e.g. a
+// name that was never declared at all.
+func resolveDeclaration(a analysis, text string, position Position) antlr.Token {
+	if a.table != nil {
+		if attr, ok := a.table.Find(text); ok {
+			return attr.GetToken()
+		}
+	}
+
+	return declarationBefore(a, text, position)
+}
+
+// declarationBefore returns the occurrence of text closest to, but not
+// after, position: for a re-declared or shadowed identifier that is a
+// better guess at "the" declaration than always resolving to the buffer's
+// very first occurrence. It falls back to the first occurrence at all if
+// text is never used at or before position.
+func declarationBefore(a analysis, text string, position Position) antlr.Token {
+	if a.tokens == nil {
+		return nil
+	}
+
+	var best, first antlr.Token
+
+	for _, candidate := range a.tokens.GetAllTokens() {
+		if candidate.GetText() != text {
+			continue
+		}
+
+		if first == nil {
+			first = candidate
+		}
+
+		line := candidate.GetLine() - 1
+		if line < position.Line || (line == position.Line && candidate.GetColumn() <= position.Character) {
+			best = candidate
+		}
+	}
+
+	if best != nil {
+		return best
+	}
+
+	return first
+}
+
+func (s *Server) handleCompletion(req request) {
+	var params positionParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		s.reply(req.ID, []CompletionItem{}, nil)
+		return
+	}
+
+	doc := s.docs.get(params.TextDocument.URI)
+	if doc == nil {
+		s.reply(req.ID, []CompletionItem{}, nil)
+		return
+	}
+
+	// Offering every identifier in the whole buffer, including ones
+	// declared further down, would suggest names that aren't actually in
+	// scope yet, so the lexical fallback below - "already used at or before
+	// the cursor" - still drives which names are offered. a.table is only
+	// consulted per-candidate below, to enrich an already-offered name with
+	// its declared expression type for completionKind.
+	a := doc.analysisForReading()
+	seen := map[string]bool{}
+	var items []CompletionItem
+
+	for _, tok := range a.tokens.GetAllTokens() {
+		text := tok.GetText()
+		if !isIdentifier(text) || seen[text] {
+			continue
+		}
+
+		line := tok.GetLine() - 1
+		if line > params.Position.Line || (line == params.Position.Line && tok.GetColumn() > params.Position.Character) {
+			continue
+		}
+
+		seen[text] = true
+
+		expressionType := identification.IDENTIFIER
+		if a.table != nil {
+			if attr, ok := a.table.Find(text); ok {
+				expressionType = attr.GetType()
+			}
+		}
+
+		items = append(items, CompletionItem{Label: text, Kind: completionKind(expressionType)})
+	}
+
+	s.reply(req.ID, items, nil)
+}
+
+func (s *Server) handleSemanticTokens(req request) {
+	var params struct {
+		TextDocument struct {
+			URI string `json:"uri"`
+		} `json:"textDocument"`
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		s.reply(req.ID, SemanticTokens{}, nil)
+		return
+	}
+
+	doc := s.docs.get(params.TextDocument.URI)
+	if doc == nil {
+		s.reply(req.ID, SemanticTokens{}, nil)
+		return
+	}
+
+	a := doc.analysisForReading()
+	var data []int
+	prevLine, prevStart := 0, 0
+
+	for _, tok := range a.tokens.GetAllTokens() {
+		tokenType, ok := semanticTokenType(a, tok.GetText())
+		if !ok {
+			continue
+		}
+
+		line := tok.GetLine() - 1
+		start := tok.GetColumn()
+
+		deltaLine := line - prevLine
+		deltaStart := start
+		if deltaLine == 0 {
+			deltaStart = start - prevStart
+		}
+
+		data = append(data, deltaLine, deltaStart, len(tok.GetText()), tokenType, 0)
+		prevLine, prevStart = line, start
+	}
+
+	s.reply(req.ID, SemanticTokens{Data: data}, nil)
+}
+
+// tokenAt finds the token covering position in a, or nil if none does
+func tokenAt(a analysis, position Position) antlr.Token {
+	if a.tokens == nil {
+		return nil
+	}
+
+	for _, tok := range a.tokens.GetAllTokens() {
+		if tok.GetLine()-1 != position.Line {
+			continue
+		}
+
+		start := tok.GetColumn()
+		end := start + len(tok.GetText())
+		if position.Character >= start && position.Character < end {
+			return tok
+		}
+	}
+
+	return nil
+}
+
+// describeToken renders tok for hover, consulting a.table for identifiers so
+// a function/hash/array shows as such instead of a generic "identifier".
+func describeToken(a analysis, tok antlr.Token) string {
+	text := tok.GetText()
+	switch {
+	case isKeyword(text):
+		return "keyword `" + text + "`"
+	case isNumber(text):
+		return "integer literal `" + text + "`"
+	case isString(text):
+		return "string literal " + text
+	case isIdentifier(text):
+		if a.table != nil {
+			if attr, ok := a.table.Find(text); ok {
+				return fmt.Sprintf("%s `%s`", expressionTypeName(attr.GetType()), text)
+			}
+		}
+
+		return "identifier `" + text + "`"
+	default:
+		return "`" + text + "`"
+	}
+}
+
+// completionKind maps an identification.ExpressionType to the LSP
+// CompletionItemKind an editor renders it with
+func completionKind(expressionType identification.ExpressionType) int {
+	switch expressionType {
+	case identification.FUNCTION:
+		return 3 // Function
+	default:
+		return 6 // Variable
+	}
+}
+
+// expressionTypeName renders an identification.ExpressionType the way it
+// reads in a hover message
+func expressionTypeName(t identification.ExpressionType) string {
+	switch t {
+	case identification.FUNCTION:
+		return "function"
+	case identification.HASH:
+		return "hash"
+	case identification.ARRAY:
+		return "array"
+	default:
+		return "identifier"
+	}
+}
+
+// semanticTokenType classifies text for textDocument/semanticTokens/full,
+// consulting a.table so a function/hash/array identifier gets its own
+// legend entry instead of the generic variable one.
+func semanticTokenType(a analysis, text string) (int, bool) {
+	switch {
+	case isKeyword(text):
+		return 0, false
+	case isNumber(text):
+		return tokenNumber, true
+	case isString(text):
+		return tokenString, true
+	case isIdentifier(text):
+		if a.table != nil {
+			if attr, ok := a.table.Find(text); ok {
+				switch attr.GetType() {
+				case identification.FUNCTION:
+					return tokenFunction, true
+				case identification.HASH:
+					return tokenHash, true
+				case identification.ARRAY:
+					return tokenArray, true
+				}
+			}
+		}
+
+		return tokenVariable, true
+	default:
+		return 0, false
+	}
+}
+
+// monkeyKeywords are reserved words that are lexically identifier-shaped
+// but must never be treated as one, e.g. by hover, go-to-definition or
+// completion.
+var monkeyKeywords = map[string]bool{
+	"let": true, "fn": true, "if": true, "else": true,
+	"return": true, "true": true, "false": true,
+}
+
+func isKeyword(text string) bool {
+	return monkeyKeywords[text]
+}
+
+func isIdentifier(text string) bool {
+	if text == "" || !unicode.IsLetter(rune(text[0])) || isKeyword(text) {
+		return false
+	}
+
+	for _, r := range text {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isNumber(text string) bool {
+	if text == "" {
+		return false
+	}
+
+	for _, r := range text {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isString(text string) bool {
+	return strings.HasPrefix(text, `"`) && strings.HasSuffix(text, `"`)
+}
+
+// M is a convenience alias for building JSON-RPC result/param objects
+type M map[string]interface{}
@@ -0,0 +1,125 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+// newTestServer builds a Server wired to an in-memory pipe, so dispatch can
+// be driven directly without a real stdio transport.
+func newTestServer() (*Server, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return &Server{docs: newStore(), out: &buf}, &buf
+}
+
+// dispatchRequest marshals params and feeds method through s.dispatch the
+// same way Serve's JSON-RPC read loop would. id is omitted for
+// notifications (didOpen/didChange/didClose never reply).
+func dispatchRequest(t *testing.T, s *Server, method string, id int, params interface{}) {
+	t.Helper()
+
+	raw, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("marshal params: %v", err)
+	}
+
+	req := request{JSONRPC: "2.0", Method: method, Params: raw}
+	if id != 0 {
+		req.ID = json.RawMessage(fmt.Sprintf("%d", id))
+	}
+
+	s.dispatch(req)
+}
+
+// nextMessage reads and decodes a single Content-Length framed JSON-RPC
+// message off r, the same framing writeMessage produces.
+func nextMessage(t *testing.T, r *bufio.Reader) map[string]interface{} {
+	t.Helper()
+
+	body, err := readMessage(r)
+	if err != nil {
+		t.Fatalf("readMessage: %v", err)
+	}
+
+	var msg map[string]interface{}
+	if err := json.Unmarshal(body, &msg); err != nil {
+		t.Fatalf("unmarshal message: %v", err)
+	}
+
+	return msg
+}
+
+// TestServerDidOpenHoverDefinitionCompletion round-trips didOpen, hover,
+// definition and completion against a fixed buffer, the handful of
+// requests an editor actually drives through this server.
+func TestServerDidOpenHoverDefinitionCompletion(t *testing.T) {
+	const uri = "file:///test.monkey"
+	const source = "let x = 5;\nlet y = x + 1;\ny;\n"
+
+	s, buf := newTestServer()
+	reader := bufio.NewReader(buf)
+
+	dispatchRequest(t, s, "textDocument/didOpen", 0, M{
+		"textDocument": M{"uri": uri, "text": source},
+	})
+
+	diagMsg := nextMessage(t, reader)
+	if method := diagMsg["method"]; method != "textDocument/publishDiagnostics" {
+		t.Fatalf("didOpen notification method = %v, want textDocument/publishDiagnostics", method)
+	}
+
+	params, _ := diagMsg["params"].(map[string]interface{})
+	if diags, _ := params["diagnostics"].([]interface{}); len(diags) != 0 {
+		t.Fatalf("didOpen diagnostics = %v, want none for a clean buffer where every declaration is used", diags)
+	}
+
+	// "x" at line 1 (0-based), column 8 is the reference inside "let y = x + 1;"
+	hoverPosition := M{"line": 1, "character": 8}
+
+	dispatchRequest(t, s, "textDocument/hover", 1, M{
+		"textDocument": M{"uri": uri},
+		"position":     hoverPosition,
+	})
+
+	hoverMsg := nextMessage(t, reader)
+	hoverResult, _ := hoverMsg["result"].(map[string]interface{})
+	if got := hoverResult["contents"]; got != "identifier `x`" {
+		t.Errorf("hover contents = %v, want %q", got, "identifier `x`")
+	}
+
+	dispatchRequest(t, s, "textDocument/definition", 2, M{
+		"textDocument": M{"uri": uri},
+		"position":     hoverPosition,
+	})
+
+	defMsg := nextMessage(t, reader)
+	defResult, _ := defMsg["result"].(map[string]interface{})
+	defRange, _ := defResult["range"].(map[string]interface{})
+	defStart, _ := defRange["start"].(map[string]interface{})
+	if line, _ := defStart["line"].(float64); line != 0 {
+		t.Errorf("definition range.start.line = %v, want 0 - x is declared on the buffer's first line", line)
+	}
+
+	// completion at the end of the second line should offer every name
+	// declared at or before the cursor
+	dispatchRequest(t, s, "textDocument/completion", 3, M{
+		"textDocument": M{"uri": uri},
+		"position":     M{"line": 1, "character": 14},
+	})
+
+	compMsg := nextMessage(t, reader)
+	items, _ := compMsg["result"].([]interface{})
+
+	labels := map[string]bool{}
+	for _, item := range items {
+		entry, _ := item.(map[string]interface{})
+		labels[fmt.Sprint(entry["label"])] = true
+	}
+
+	if !labels["x"] || !labels["y"] {
+		t.Errorf("completion labels = %v, want to include both x and y", labels)
+	}
+}
@@ -0,0 +1,89 @@
+package lsp
+
+import "github.com/Mario-Jimenez/gocompiler/diagnostics"
+
+// Position is a zero-based line/character location, per the LSP spec
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range spans from Start to End within a document
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location points to a Range within a specific document
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// Diagnostic is the wire shape for textDocument/publishDiagnostics
+type Diagnostic struct {
+	Range    Range  `json:"range"`
+	Severity int    `json:"severity"`
+	Code     string `json:"code,omitempty"`
+	Message  string `json:"message"`
+}
+
+// severity maps our compiler severities to LSP's 1 (error) - 4 (hint) scale
+func severity(s diagnostics.Severity) int {
+	switch s {
+	case diagnostics.Warning:
+		return 2
+	case diagnostics.Hint:
+		return 4
+	default:
+		return 1
+	}
+}
+
+// toDiagnostic converts a compiler diagnostic into its LSP wire shape,
+// translating the compiler's 1-based lines into 0-based LSP ones. Columns
+// need no translation - diagnostics.Range already uses the same 0-based,
+// end-exclusive convention the LSP spec uses for Position.
+func toDiagnostic(d diagnostics.Diagnostic) Diagnostic {
+	return Diagnostic{
+		Range: Range{
+			Start: Position{Line: d.Range.StartLine - 1, Character: d.Range.StartCol},
+			End:   Position{Line: d.Range.EndLine - 1, Character: d.Range.EndCol},
+		},
+		Severity: severity(d.Severity),
+		Code:     d.Code,
+		Message:  d.Message,
+	}
+}
+
+// CompletionItem is a single entry offered by textDocument/completion
+type CompletionItem struct {
+	Label  string `json:"label"`
+	Kind   int    `json:"kind"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Hover is the result of textDocument/hover
+type Hover struct {
+	Contents string `json:"contents"`
+	Range    Range  `json:"range,omitempty"`
+}
+
+// SemanticTokens is the result of textDocument/semanticTokens/full, encoded
+// as the LSP spec's relative (deltaLine, deltaStart, length, tokenType,
+// tokenModifiers) quintuplets
+type SemanticTokens struct {
+	Data []int `json:"data"`
+}
+
+// Monkey's semantic token legend, in the order tokenType indices below refer to
+var semanticTokenTypes = []string{"variable", "function", "struct", "array", "number", "string"}
+
+const (
+	tokenVariable = iota
+	tokenFunction
+	tokenHash
+	tokenArray
+	tokenNumber
+	tokenString
+)
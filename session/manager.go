@@ -0,0 +1,74 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Manager tracks active sessions keyed by UUID, each with its own
+// instructions file under dir so multi-tenant compile/run requests never
+// share state.
+type Manager struct {
+	mu       sync.RWMutex
+	sessions map[string]*Session
+	dir      string
+}
+
+// NewManager creates a session manager that stores instructions files under
+// dir, creating dir if it does not already exist
+func NewManager(dir string) *Manager {
+	os.MkdirAll(dir, os.ModePerm)
+
+	return &Manager{
+		sessions: make(map[string]*Session),
+		dir:      dir,
+	}
+}
+
+// Create starts a new session and returns it
+func (m *Manager) Create() *Session {
+	id := uuid.New().String()
+
+	s := &Session{
+		ID:       id,
+		basePath: filepath.Join(m.dir, id),
+	}
+
+	m.mu.Lock()
+	m.sessions[id] = s
+	m.mu.Unlock()
+
+	return s
+}
+
+// Get returns the session for id, or false if it does not exist
+func (m *Manager) Get(id string) (*Session, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, ok := m.sessions[id]
+	return s, ok
+}
+
+// Delete removes the session and its instructions file from disk, reporting
+// whether the session existed
+func (m *Manager) Delete(id string) bool {
+	m.mu.Lock()
+	s, ok := m.sessions[id]
+	if ok {
+		delete(m.sessions, id)
+	}
+	m.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	_, instructionsPath := s.Artifact()
+	os.Remove(instructionsPath)
+
+	return true
+}
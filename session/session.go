@@ -0,0 +1,68 @@
+package session
+
+import (
+	"sync"
+
+	"github.com/Mario-Jimenez/gocompiler/identification"
+)
+
+// Session holds the compilation state for a single client, identified by a
+// UUID. Each session gets its own instructions file on disk so concurrent
+// compile/run requests from different clients never clobber each other.
+type Session struct {
+	mu sync.Mutex
+
+	// ID uniquely identifies the session
+	ID string
+
+	// basePath is where this session's generated code is saved, without the
+	// backend-specific extension
+	basePath string
+
+	// InstructionsPath is where this session's generated code is saved
+	InstructionsPath string
+
+	// Target is the code generation backend used for the last successful compile
+	Target string
+
+	// Table is the identification table produced by the last successful compile
+	Table *identification.Table
+
+	// Tree is the parse tree produced by the last successful compile
+	Tree interface{}
+}
+
+// SetAnalysis stores the identification table and parse tree from the last
+// successful compile, replacing any previous snapshot.
+func (s *Session) SetAnalysis(table *identification.Table, tree interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Table = table
+	s.Tree = tree
+}
+
+// SetArtifact records which backend produced the session's current
+// instructions file and where it was written, so a later run knows how to
+// execute it. It returns the computed instructions path so the caller that
+// writes the artifact never has to read s.InstructionsPath directly and
+// race a concurrent SetArtifact/Delete on the same session.
+func (s *Session) SetArtifact(target, extension string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Target = target
+	s.InstructionsPath = s.basePath + extension
+
+	return s.InstructionsPath
+}
+
+// Artifact returns the backend target and instructions path from the last
+// successful compile, guarding against a concurrent SetArtifact from
+// another request on the same session.
+func (s *Session) Artifact() (target, instructionsPath string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.Target, s.InstructionsPath
+}
@@ -0,0 +1,69 @@
+package codegenerator
+
+import (
+	"fmt"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// Backend is implemented by every code-generation target. Visit walks the
+// parsed program and accumulates generated code; Code returns the finished
+// artifact once the walk is done.
+type Backend interface {
+	Visit(tree antlr.ParseTree) interface{}
+	Code() string
+	Extension() string
+	MIMEType() string
+}
+
+// errorBackend is implemented by backends that can hit a construct they have
+// no way to represent, e.g. watVisitor's module with no memory section or
+// function table. It's kept separate from Backend, rather than added to it,
+// because most backends (the native VM, JS) support the whole grammar and
+// would have nothing to report.
+type errorBackend interface {
+	Err() error
+}
+
+// ErrIfUnsupported returns the error recorded by backend's walk if it hit a
+// construct it can't represent, or nil if backend doesn't track that or
+// never hit one.
+func ErrIfUnsupported(backend Backend) error {
+	if b, ok := backend.(errorBackend); ok {
+		return b.Err()
+	}
+
+	return nil
+}
+
+// Target names accepted on the Compile request's "target" field
+const (
+	TargetMonkeyVM = "monkeyvm"
+	TargetJS       = "js"
+	TargetWAT      = "wasm-text"
+)
+
+// NewBackend builds the code generation backend for target, defaulting to
+// the native Monkey VM bytecode backend consumed by cli.VM
+func NewBackend(target string) (Backend, error) {
+	switch target {
+	case "", TargetMonkeyVM:
+		return NewVisitor(), nil
+	case TargetJS:
+		return newJSVisitor(), nil
+	case TargetWAT:
+		return newWATVisitor(), nil
+	default:
+		return nil, fmt.Errorf("unknown code generation target %q", target)
+	}
+}
+
+// Extension returns the file extension used for the native VM bytecode artifact
+func (v *visitor) Extension() string {
+	return ".mo"
+}
+
+// MIMEType returns the MIME type advertised for the native VM bytecode artifact
+func (v *visitor) MIMEType() string {
+	return "application/octet-stream"
+}
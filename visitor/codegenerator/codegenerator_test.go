@@ -0,0 +1,18 @@
+package codegenerator
+
+import (
+	"github.com/Mario-Jimenez/gocompiler/parser"
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// parseProgram lexes and parses source the same way the real compile
+// pipeline does, giving each backend's tests a parse tree to walk without
+// going through the handler package.
+func parseProgram(source string) antlr.ParseTree {
+	input := antlr.NewInputStream(source)
+
+	lexer := parser.NewMonkeyLexer(input)
+	tokens := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
+
+	return parser.NewMonkeyParser(tokens).Program()
+}
@@ -0,0 +1,431 @@
+package codegenerator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Mario-Jimenez/gocompiler/parser"
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// jsVisitor emits ES2020 source by walking the same ANTLR parse tree as the
+// native bytecode backend. It accumulates generated expression fragments on
+// a stack as it walks, the same traversal order the bytecode visitor uses,
+// joining fragments back together once a rule completes.
+type jsVisitor struct {
+	*parser.BaseMonkeyVisitor
+	stack []string
+}
+
+// newJSVisitor creates the JavaScript code generation backend
+func newJSVisitor() *jsVisitor {
+	return &jsVisitor{
+		BaseMonkeyVisitor: &parser.BaseMonkeyVisitor{},
+	}
+}
+
+// Visit overrides the one promoted from BaseMonkeyVisitor so that
+// tree.Accept dispatches back into jsVisitor's own VisitXxx overrides
+// instead of the embedded base visitor's no-op ones.
+func (v *jsVisitor) Visit(tree antlr.ParseTree) interface{} {
+	return tree.Accept(v)
+}
+
+func (v *jsVisitor) push(fragment string) {
+	v.stack = append(v.stack, fragment)
+}
+
+func (v *jsVisitor) pop() string {
+	last := len(v.stack) - 1
+	fragment := v.stack[last]
+	v.stack = v.stack[:last]
+	return fragment
+}
+
+/*
+	program: statement* EOF ;
+
+	A top-level expressionStatement's value is otherwise computed and
+	discarded, leaving the run/run-stream endpoints nothing to hand back
+	to the caller - so, unlike a nested block's expressionStatement, it is
+	wrapped in a console.log instead of emitted bare.
+*/
+
+func (v *jsVisitor) VisitProgram(ctx *parser.ProgramContext) interface{} {
+	for _, statement := range ctx.AllStatement() {
+		if expressionStatement, ok := statement.(*parser.ExpressionStatementContext); ok {
+			v.Visit(expressionStatement.Expression())
+			v.push(fmt.Sprintf("console.log(%s);", v.pop()))
+			continue
+		}
+
+		v.Visit(statement)
+	}
+
+	return nil
+}
+
+/*
+	statement: 'let' Identifier '=' expression ';' # letStatement ;
+*/
+
+func (v *jsVisitor) VisitLetStatement(ctx *parser.LetStatementContext) interface{} {
+	v.Visit(ctx.Expression())
+
+	v.push(fmt.Sprintf("let %s = %s;", ctx.Identifier().GetText(), v.pop()))
+
+	return nil
+}
+
+/*
+	statement: 'return' expression ';' # returnStatement ;
+*/
+
+func (v *jsVisitor) VisitReturnStatement(ctx *parser.ReturnStatementContext) interface{} {
+	v.Visit(ctx.Expression())
+
+	v.push(fmt.Sprintf("return %s;", v.pop()))
+
+	return nil
+}
+
+/*
+	statement:
+		primaryExpression '[' expression ']' '=' expression ';'
+			# indexAssignmentStatement
+		;
+*/
+
+func (v *jsVisitor) VisitIndexAssignmentStatement(ctx *parser.IndexAssignmentStatementContext) interface{} {
+	v.Visit(ctx.PrimaryExpression())
+	target := v.pop()
+
+	v.Visit(ctx.Expression(0))
+	key := v.pop()
+
+	v.Visit(ctx.Expression(1))
+	value := v.pop()
+
+	v.push(fmt.Sprintf("%s[%s] = %s;", target, key, value))
+
+	return nil
+}
+
+/*
+	statement: expression ';' # expressionStatement ;
+*/
+
+func (v *jsVisitor) VisitExpressionStatement(ctx *parser.ExpressionStatementContext) interface{} {
+	v.Visit(ctx.Expression())
+
+	v.push(v.pop() + ";")
+
+	return nil
+}
+
+/*
+	block: '{' statement* '}' ;
+
+	Monkey's block is implicitly its last statement's value, the same
+	convention the WAT backend gets for free from Wasm's own implicit
+	block results (wat.go): a bare trailing expressionStatement is
+	compiled as a JavaScript return rather than a discarded expression, so
+	the IIFE VisitIfExpression wraps an if/else branch in, and the
+	function VisitFunctionLiteral emits, actually produce a value.
+*/
+
+func (v *jsVisitor) VisitBlock(ctx *parser.BlockContext) interface{} {
+	statements := ctx.AllStatement()
+
+	lines := make([]string, len(statements))
+	for i, statement := range statements {
+		if i == len(statements)-1 {
+			if expressionStatement, ok := statement.(*parser.ExpressionStatementContext); ok {
+				v.Visit(expressionStatement.Expression())
+				lines[i] = fmt.Sprintf("return %s;", v.pop())
+				continue
+			}
+		}
+
+		v.Visit(statement)
+		lines[i] = v.pop()
+	}
+
+	v.push("{\n" + strings.Join(lines, "\n") + "\n}")
+
+	return nil
+}
+
+/*
+	expression:
+		additionExpression (
+			comparisonFactor additionExpression
+		)* # comparisonTree
+		;
+*/
+
+func (v *jsVisitor) VisitComparisonTree(ctx *parser.ComparisonTreeContext) interface{} {
+	v.Visit(ctx.AdditionExpression(0))
+
+	totalBranches := len(ctx.AllAdditionExpression())
+	index := 1
+	for index < totalBranches {
+		v.Visit(ctx.AdditionExpression(index))
+		operator := ctx.ComparisonFactor(index - 1).GetText()
+
+		right := v.pop()
+		left := v.pop()
+		v.push(fmt.Sprintf("(%s %s %s)", left, jsComparisonOperator(operator), right))
+
+		index++
+	}
+
+	return nil
+}
+
+// jsComparisonOperator maps Monkey's == and != to JavaScript's strict
+// equality operators so a string/integer comparison can't silently coerce.
+func jsComparisonOperator(operator string) string {
+	switch operator {
+	case "==":
+		return "==="
+	case "!=":
+		return "!=="
+	default:
+		return operator
+	}
+}
+
+/*
+	primaryExpression: '(' expression ')' # groupedExpression ;
+*/
+
+func (v *jsVisitor) VisitGroupedExpression(ctx *parser.GroupedExpressionContext) interface{} {
+	v.Visit(ctx.Expression())
+
+	v.push("(" + v.pop() + ")")
+
+	return nil
+}
+
+/*
+	primaryExpression:
+		'[' (expression (',' expression)*)? ']' # arrayLiteral
+		;
+*/
+
+func (v *jsVisitor) VisitArrayLiteral(ctx *parser.ArrayLiteralContext) interface{} {
+	elements := make([]string, len(ctx.AllExpression()))
+	for i, element := range ctx.AllExpression() {
+		v.Visit(element)
+		elements[i] = v.pop()
+	}
+
+	v.push("[" + strings.Join(elements, ", ") + "]")
+
+	return nil
+}
+
+/*
+	primaryExpression:
+		'{' (hashPair (',' hashPair)*)? '}' # hashLiteral
+		;
+	hashPair: expression ':' expression ;
+*/
+
+func (v *jsVisitor) VisitHashLiteral(ctx *parser.HashLiteralContext) interface{} {
+	pairs := make([]string, len(ctx.AllHashPair()))
+	for i, pair := range ctx.AllHashPair() {
+		v.Visit(pair.Key())
+		key := v.pop()
+
+		v.Visit(pair.Value())
+		value := v.pop()
+
+		pairs[i] = fmt.Sprintf("[%s]: %s", key, value)
+	}
+
+	v.push("{" + strings.Join(pairs, ", ") + "}")
+
+	return nil
+}
+
+/*
+	primaryExpression:
+		'if' '(' expression ')' block ('else' block)? # ifExpression
+		;
+
+	Monkey's if is an expression whose value is its chosen block's last
+	evaluated statement; JavaScript has no if-expression, so the block is
+	wrapped in an immediately-invoked function whose return value -
+	explicit or implicit via VisitBlock's trailing-expression handling -
+	becomes the expression's value.
+*/
+
+func (v *jsVisitor) VisitIfExpression(ctx *parser.IfExpressionContext) interface{} {
+	v.Visit(ctx.Expression())
+	condition := v.pop()
+
+	v.Visit(ctx.Block(0))
+	consequence := v.pop()
+
+	alternative := "{}"
+	if len(ctx.AllBlock()) > 1 {
+		v.Visit(ctx.Block(1))
+		alternative = v.pop()
+	}
+
+	v.push(fmt.Sprintf("(%s ? (function() %s)() : (function() %s)())", condition, consequence, alternative))
+
+	return nil
+}
+
+/*
+	primaryExpression:
+		'fn' '(' (Identifier (',' Identifier)*)? ')' block # functionLiteral
+		;
+*/
+
+func (v *jsVisitor) VisitFunctionLiteral(ctx *parser.FunctionLiteralContext) interface{} {
+	params := make([]string, len(ctx.AllIdentifier()))
+	for i, id := range ctx.AllIdentifier() {
+		params[i] = id.GetText()
+	}
+
+	v.Visit(ctx.Block())
+
+	v.push(fmt.Sprintf("function(%s) %s", strings.Join(params, ", "), v.pop()))
+
+	return nil
+}
+
+/*
+	primaryExpression:
+		primaryExpression '(' (expression (',' expression)*)? ')' # callExpression
+		;
+*/
+
+func (v *jsVisitor) VisitCallExpression(ctx *parser.CallExpressionContext) interface{} {
+	v.Visit(ctx.PrimaryExpression())
+	callee := v.pop()
+
+	args := make([]string, len(ctx.AllExpression()))
+	for i, arg := range ctx.AllExpression() {
+		v.Visit(arg)
+		args[i] = v.pop()
+	}
+
+	v.push(fmt.Sprintf("%s(%s)", callee, strings.Join(args, ", ")))
+
+	return nil
+}
+
+/*
+	primaryExpression:
+		primaryExpression '[' expression ']' # indexExpression
+		;
+*/
+
+func (v *jsVisitor) VisitIndexExpression(ctx *parser.IndexExpressionContext) interface{} {
+	v.Visit(ctx.PrimaryExpression())
+	target := v.pop()
+
+	v.Visit(ctx.Expression())
+	index := v.pop()
+
+	v.push(fmt.Sprintf("%s[%s]", target, index))
+
+	return nil
+}
+
+/*
+	additionExpression:
+		multiplicationExpression (
+			additionFactor multiplicationExpression
+		)* # additionTree
+		;
+*/
+
+func (v *jsVisitor) VisitAdditionTree(ctx *parser.AdditionTreeContext) interface{} {
+	v.Visit(ctx.MultiplicationExpression(0))
+
+	totalBranches := len(ctx.AllMultiplicationExpression())
+	index := 1
+	for index < totalBranches {
+		v.Visit(ctx.MultiplicationExpression(index))
+		operator := ctx.AdditionFactor(index - 1).GetText()
+
+		right := v.pop()
+		left := v.pop()
+		v.push(fmt.Sprintf("(%s %s %s)", left, operator, right))
+
+		index++
+	}
+
+	return nil
+}
+
+/*
+	multiplicationExpression:
+		primaryExpression (
+			multiplicationFactor primaryExpression
+		)* # multiplicationTree
+		;
+*/
+
+func (v *jsVisitor) VisitMultiplicationTree(ctx *parser.MultiplicationTreeContext) interface{} {
+	v.Visit(ctx.PrimaryExpression(0))
+
+	totalBranches := len(ctx.AllPrimaryExpression())
+	index := 1
+	for index < totalBranches {
+		v.Visit(ctx.PrimaryExpression(index))
+		operator := ctx.MultiplicationFactor(index - 1).GetText()
+
+		right := v.pop()
+		left := v.pop()
+		v.push(fmt.Sprintf("(%s %s %s)", left, operator, right))
+
+		index++
+	}
+
+	return nil
+}
+
+// Integer, string, boolean and identifier literals push their source text
+// unchanged: Monkey's syntax for all four is already valid JavaScript.
+
+func (v *jsVisitor) VisitIntegerLiteral(ctx *parser.IntegerLiteralContext) interface{} {
+	v.push(ctx.GetText())
+	return nil
+}
+
+func (v *jsVisitor) VisitStringLiteral(ctx *parser.StringLiteralContext) interface{} {
+	v.push(ctx.GetText())
+	return nil
+}
+
+func (v *jsVisitor) VisitBooleanLiteral(ctx *parser.BooleanLiteralContext) interface{} {
+	v.push(ctx.GetText())
+	return nil
+}
+
+func (v *jsVisitor) VisitIdentifierLiteral(ctx *parser.IdentifierLiteralContext) interface{} {
+	v.push(ctx.GetText())
+	return nil
+}
+
+// Code returns the generated JavaScript source
+func (v *jsVisitor) Code() string {
+	return strings.Join(v.stack, "\n")
+}
+
+// Extension returns the file extension for the JavaScript artifact
+func (v *jsVisitor) Extension() string {
+	return ".js"
+}
+
+// MIMEType returns the MIME type advertised for the JavaScript artifact
+func (v *jsVisitor) MIMEType() string {
+	return "application/javascript"
+}
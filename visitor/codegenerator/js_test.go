@@ -0,0 +1,72 @@
+package codegenerator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJSVisitorCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{
+			name:   "a top-level expression statement is printed, not discarded",
+			source: "1 + 2;",
+			want:   []string{"console.log((1 + 2));"},
+		},
+		{
+			name:   "a let statement is not wrapped in console.log",
+			source: "let x = 1;",
+			want:   []string{"let x = 1;"},
+		},
+		{
+			name:   "a function body's bare trailing expression is an implicit return",
+			source: "let square = fn(x) { x * x; }; square(7);",
+			want: []string{
+				"function(x) {\nreturn (x * x);\n}",
+				"console.log(square(7));",
+			},
+		},
+		{
+			name:   "an if expression's branch value comes from VisitBlock's implicit return",
+			source: "if (true) { 1; } else { 2; };",
+			want: []string{
+				"(function() {\nreturn 1;\n})()",
+				"(function() {\nreturn 2;\n})()",
+			},
+		},
+		{
+			name:   "an explicit return inside a block is left as-is",
+			source: "let f = fn(x) { return x; };",
+			want:   []string{"return x;"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newJSVisitor()
+			v.Visit(parseProgram(tt.source))
+			code := v.Code()
+
+			for _, want := range tt.want {
+				if !strings.Contains(code, want) {
+					t.Errorf("Code() = %q, want substring %q", code, want)
+				}
+			}
+		})
+	}
+}
+
+func TestJSVisitorExtensionAndMIMEType(t *testing.T) {
+	v := newJSVisitor()
+
+	if got := v.Extension(); got != ".js" {
+		t.Errorf("Extension() = %q, want %q", got, ".js")
+	}
+
+	if got := v.MIMEType(); got != "application/javascript" {
+		t.Errorf("MIMEType() = %q, want %q", got, "application/javascript")
+	}
+}
@@ -0,0 +1,384 @@
+package codegenerator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Mario-Jimenez/gocompiler/parser"
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// watVisitor emits a WebAssembly text module by walking the same ANTLR
+// parse tree as the native bytecode backend. Monkey's stack discipline maps
+// directly onto Wasm's: visiting an operand appends the instructions that
+// leave its value on the stack, and visiting an operator appends the
+// instruction that combines the top of stack.
+//
+// The module it emits is a single function returning one i32, so only the
+// integer-valued subset of Monkey is supported: let/return, if/else, and
+// integer/boolean arithmetic and comparisons. Strings, arrays, hashes and
+// function literals/calls have no representation in a module with no
+// memory section or function table; visiting one of them doesn't panic or
+// emit anything for it, but records it via unsupported so Err reports a
+// failure once the walk is done instead of the caller writing out a module
+// that looks plausible but silently dropped part of the program.
+type watVisitor struct {
+	*parser.BaseMonkeyVisitor
+	instructions []string
+	locals       []string
+	err          error
+}
+
+// newWATVisitor creates the WebAssembly text format code generation backend
+func newWATVisitor() *watVisitor {
+	return &watVisitor{
+		BaseMonkeyVisitor: &parser.BaseMonkeyVisitor{},
+	}
+}
+
+// Visit overrides the one promoted from BaseMonkeyVisitor so that
+// tree.Accept dispatches back into watVisitor's own VisitXxx overrides
+// instead of the embedded base visitor's no-op ones.
+func (v *watVisitor) Visit(tree antlr.ParseTree) interface{} {
+	return tree.Accept(v)
+}
+
+func (v *watVisitor) emit(instruction string) {
+	v.instructions = append(v.instructions, instruction)
+}
+
+// unsupported records that construct has no representation in a module with
+// no memory section or function table, keeping the first one encountered -
+// later ones are most likely the same root cause cascading through the rest
+// of the tree. It never panics or emits anything for the construct, so the
+// walk can keep running to completion; Err reports the failure once it's
+// done so the caller can reject the compile instead of writing out a module
+// that looks plausible but silently dropped part of the program.
+func (v *watVisitor) unsupported(construct string) {
+	if v.err == nil {
+		v.err = fmt.Errorf("%s has no representation in the wasm-text target", construct)
+	}
+}
+
+// Err reports the first unsupported construct the walk encountered, or nil
+// if every construct visited could be represented.
+func (v *watVisitor) Err() error {
+	return v.err
+}
+
+// declareLocal registers name as one of $main's locals the first time it is
+// let-bound, so Code can declare it once up front the way Wasm requires.
+func (v *watVisitor) declareLocal(name string) {
+	for _, local := range v.locals {
+		if local == name {
+			return
+		}
+	}
+
+	v.locals = append(v.locals, name)
+}
+
+/*
+	program: statement* EOF ;
+*/
+
+func (v *watVisitor) VisitProgram(ctx *parser.ProgramContext) interface{} {
+	for _, statement := range ctx.AllStatement() {
+		v.Visit(statement)
+	}
+
+	return nil
+}
+
+/*
+	statement: 'let' Identifier '=' expression ';' # letStatement ;
+*/
+
+func (v *watVisitor) VisitLetStatement(ctx *parser.LetStatementContext) interface{} {
+	v.Visit(ctx.Expression())
+
+	name := ctx.Identifier().GetText()
+	v.declareLocal(name)
+	v.emit(fmt.Sprintf("local.set $%s", name))
+
+	return nil
+}
+
+/*
+	statement: 'return' expression ';' # returnStatement ;
+*/
+
+func (v *watVisitor) VisitReturnStatement(ctx *parser.ReturnStatementContext) interface{} {
+	v.Visit(ctx.Expression())
+	v.emit("return")
+
+	return nil
+}
+
+/*
+	statement: expression ';' # expressionStatement ;
+
+	The value it leaves on the stack only becomes $main's (or an if
+	branch's) result when this is the block's final statement - the same
+	convention Wasm's own implicit block results follow.
+*/
+
+func (v *watVisitor) VisitExpressionStatement(ctx *parser.ExpressionStatementContext) interface{} {
+	return v.Visit(ctx.Expression())
+}
+
+/*
+	block: '{' statement* '}' ;
+*/
+
+func (v *watVisitor) VisitBlock(ctx *parser.BlockContext) interface{} {
+	for _, statement := range ctx.AllStatement() {
+		v.Visit(statement)
+	}
+
+	return nil
+}
+
+/*
+	expression:
+		additionExpression (
+			comparisonFactor additionExpression
+		)* # comparisonTree
+		;
+*/
+
+func (v *watVisitor) VisitComparisonTree(ctx *parser.ComparisonTreeContext) interface{} {
+	v.Visit(ctx.AdditionExpression(0))
+
+	totalBranches := len(ctx.AllAdditionExpression())
+	index := 1
+	for index < totalBranches {
+		v.Visit(ctx.AdditionExpression(index))
+
+		switch ctx.ComparisonFactor(index - 1).GetText() {
+		case "<":
+			v.emit("i32.lt_s")
+		case ">":
+			v.emit("i32.gt_s")
+		case "!=":
+			v.emit("i32.ne")
+		default:
+			v.emit("i32.eq")
+		}
+
+		index++
+	}
+
+	return nil
+}
+
+/*
+	primaryExpression: '(' expression ')' # groupedExpression ;
+*/
+
+func (v *watVisitor) VisitGroupedExpression(ctx *parser.GroupedExpressionContext) interface{} {
+	return v.Visit(ctx.Expression())
+}
+
+/*
+	primaryExpression:
+		'if' '(' expression ')' block ('else' block)? # ifExpression
+		;
+*/
+
+func (v *watVisitor) VisitIfExpression(ctx *parser.IfExpressionContext) interface{} {
+	v.Visit(ctx.Expression())
+	v.emit("if (result i32)")
+
+	v.Visit(ctx.Block(0))
+
+	v.emit("else")
+	if len(ctx.AllBlock()) > 1 {
+		v.Visit(ctx.Block(1))
+	} else {
+		v.emit("i32.const 0")
+	}
+
+	v.emit("end")
+
+	return nil
+}
+
+/*
+	additionExpression:
+		multiplicationExpression (
+			additionFactor multiplicationExpression
+		)* # additionTree
+		;
+*/
+
+func (v *watVisitor) VisitAdditionTree(ctx *parser.AdditionTreeContext) interface{} {
+	v.Visit(ctx.MultiplicationExpression(0))
+
+	totalBranches := len(ctx.AllMultiplicationExpression())
+	index := 1
+	for index < totalBranches {
+		v.Visit(ctx.MultiplicationExpression(index))
+
+		switch ctx.AdditionFactor(index - 1).GetText() {
+		case "-":
+			v.emit("i32.sub")
+		default:
+			v.emit("i32.add")
+		}
+
+		index++
+	}
+
+	return nil
+}
+
+/*
+	multiplicationExpression:
+		primaryExpression (
+			multiplicationFactor primaryExpression
+		)* # multiplicationTree
+		;
+*/
+
+func (v *watVisitor) VisitMultiplicationTree(ctx *parser.MultiplicationTreeContext) interface{} {
+	v.Visit(ctx.PrimaryExpression(0))
+
+	totalBranches := len(ctx.AllPrimaryExpression())
+	index := 1
+	for index < totalBranches {
+		v.Visit(ctx.PrimaryExpression(index))
+
+		switch ctx.MultiplicationFactor(index - 1).GetText() {
+		case "/":
+			v.emit("i32.div_s")
+		default:
+			v.emit("i32.mul")
+		}
+
+		index++
+	}
+
+	return nil
+}
+
+// VisitIntegerLiteral emits the instruction that leaves an integer literal
+// operand's value on the stack.
+func (v *watVisitor) VisitIntegerLiteral(ctx *parser.IntegerLiteralContext) interface{} {
+	v.emit(fmt.Sprintf("i32.const %s", ctx.GetText()))
+	return nil
+}
+
+// VisitBooleanLiteral emits Monkey's true/false as Wasm's canonical i32
+// booleans, 1 and 0.
+func (v *watVisitor) VisitBooleanLiteral(ctx *parser.BooleanLiteralContext) interface{} {
+	value := "0"
+	if ctx.GetText() == "true" {
+		value = "1"
+	}
+
+	v.emit(fmt.Sprintf("i32.const %s", value))
+
+	return nil
+}
+
+// VisitIdentifierLiteral emits the instruction that reads a let-bound
+// local's current value onto the stack.
+func (v *watVisitor) VisitIdentifierLiteral(ctx *parser.IdentifierLiteralContext) interface{} {
+	v.emit(fmt.Sprintf("local.get $%s", ctx.GetText()))
+	return nil
+}
+
+/*
+	primaryExpression: String # stringLiteral ;
+*/
+
+func (v *watVisitor) VisitStringLiteral(ctx *parser.StringLiteralContext) interface{} {
+	v.unsupported("a string literal")
+	return nil
+}
+
+/*
+	primaryExpression: '[' (expression (',' expression)*)? ']' # arrayLiteral ;
+*/
+
+func (v *watVisitor) VisitArrayLiteral(ctx *parser.ArrayLiteralContext) interface{} {
+	v.unsupported("an array literal")
+	return nil
+}
+
+/*
+	primaryExpression:
+		'{' (hashPair (',' hashPair)*)? '}' # hashLiteral
+		;
+	hashPair: expression ':' expression ;
+*/
+
+func (v *watVisitor) VisitHashLiteral(ctx *parser.HashLiteralContext) interface{} {
+	v.unsupported("a hash literal")
+	return nil
+}
+
+/*
+	primaryExpression:
+		'fn' '(' (Identifier (',' Identifier)*)? ')' block # functionLiteral
+		;
+*/
+
+func (v *watVisitor) VisitFunctionLiteral(ctx *parser.FunctionLiteralContext) interface{} {
+	v.unsupported("a function literal")
+	return nil
+}
+
+/*
+	primaryExpression: primaryExpression '(' (expression (',' expression)*)? ')' # callExpression ;
+*/
+
+func (v *watVisitor) VisitCallExpression(ctx *parser.CallExpressionContext) interface{} {
+	v.unsupported("a function call")
+	return nil
+}
+
+/*
+	primaryExpression: primaryExpression '[' expression ']' # indexExpression ;
+*/
+
+func (v *watVisitor) VisitIndexExpression(ctx *parser.IndexExpressionContext) interface{} {
+	v.unsupported("an index expression")
+	return nil
+}
+
+/*
+	statement: primaryExpression '[' expression ']' '=' expression ';' # indexAssignmentStatement ;
+*/
+
+func (v *watVisitor) VisitIndexAssignmentStatement(ctx *parser.IndexAssignmentStatementContext) interface{} {
+	v.unsupported("an index assignment")
+	return nil
+}
+
+// Code returns the generated WebAssembly text module
+func (v *watVisitor) Code() string {
+	var module strings.Builder
+
+	module.WriteString("(module\n  (func $main (result i32)\n")
+	for _, local := range v.locals {
+		fmt.Fprintf(&module, "    (local $%s i32)\n", local)
+	}
+	for _, instruction := range v.instructions {
+		fmt.Fprintf(&module, "    %s\n", instruction)
+	}
+	module.WriteString("  )\n  (export \"main\" (func $main))\n)\n")
+
+	return module.String()
+}
+
+// Extension returns the file extension for the WebAssembly text artifact
+func (v *watVisitor) Extension() string {
+	return ".wat"
+}
+
+// MIMEType returns the MIME type advertised for the WebAssembly text artifact
+func (v *watVisitor) MIMEType() string {
+	return "text/plain"
+}
@@ -0,0 +1,79 @@
+package codegenerator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWATVisitorCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{
+			name:   "arithmetic leaves its value on the stack for $main's result",
+			source: "1 + 2 * 3;",
+			want:   []string{"i32.const 1", "i32.const 2", "i32.const 3", "i32.mul", "i32.add"},
+		},
+		{
+			name:   "a let-bound local is declared once and read back",
+			source: "let x = 5; x + 1;",
+			want:   []string{"(local $x i32)", "local.set $x", "local.get $x"},
+		},
+		{
+			name:   "an if expression compiles to a result-typed if/else block",
+			source: "if (1 < 2) { 10; } else { 20; };",
+			want:   []string{"if (result i32)", "i32.lt_s", "i32.const 10", "i32.const 20", "else", "end"},
+		},
+		{
+			name:   "the module exports $main but no WASI _start",
+			source: "1;",
+			want:   []string{`(export "main" (func $main))`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newWATVisitor()
+			v.Visit(parseProgram(tt.source))
+
+			if err := v.Err(); err != nil {
+				t.Fatalf("Err() = %v, want nil", err)
+			}
+
+			code := v.Code()
+			for _, want := range tt.want {
+				if !strings.Contains(code, want) {
+					t.Errorf("Code() = %q, want substring %q", code, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWATVisitorUnsupported(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{name: "a string literal has no representation", source: `"hi";`},
+		{name: "an array literal has no representation", source: "[1, 2];"},
+		{name: "a hash literal has no representation", source: `{"a": 1};`},
+		{name: "a function literal has no representation", source: "fn(x) { x; };"},
+		{name: "a function call has no representation", source: "len([1]);"},
+		{name: "an index expression has no representation", source: "[1][0];"},
+		{name: "an index assignment has no representation", source: "x[0] = 1;"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newWATVisitor()
+			v.Visit(parseProgram(tt.source))
+
+			if err := v.Err(); err == nil {
+				t.Errorf("Err() = nil, want a non-nil error for %q", tt.source)
+			}
+		})
+	}
+}
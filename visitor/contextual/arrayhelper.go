@@ -0,0 +1,109 @@
+package contextual
+
+import (
+	"github.com/Mario-Jimenez/gocompiler/diagnostics"
+	"github.com/Mario-Jimenez/gocompiler/identification"
+)
+
+// ArrayType is the transient element type arrayHelper tracks while walking
+// an array literal, the same way HashType is to hashHelper.
+type ArrayType int
+
+const (
+	AUNKNOWN ArrayType = iota
+	AINTEGER
+	ASTRING
+	ACOMPLEX
+)
+
+type array struct {
+	element ArrayType
+}
+
+// arrayHelper tracks the ArrayType being unified for the array literal
+// currently being visited, the same way hashHelper tracks a hash literal's
+// key type, so nested array literals each get their own element tracking
+// and checkElement keeps unifying as elements are visited.
+type arrayHelper struct {
+	levels int
+	arrays []array
+}
+
+func newArrayHelper() *arrayHelper {
+	return &arrayHelper{
+		levels: -1,
+		arrays: []array{},
+	}
+}
+
+func (a *arrayHelper) newArray() {
+	a.levels++
+	a.arrays = append(a.arrays, array{})
+}
+
+func (a *arrayHelper) setType(element ArrayType) {
+	a.arrays[a.levels].element = element
+}
+
+func (a *arrayHelper) getType() ArrayType {
+	if a.levels > -1 {
+		return a.arrays[a.levels].element
+	}
+
+	return ACOMPLEX
+}
+
+// checkElement unifies elementType into the array literal currently being
+// built and reports a diagnostic if it does not match the elements seen so
+// far at this level, the same way hashHelper.checkKey does for hash
+// literals. An element whose own type couldn't be statically determined
+// (e.g. a computed expression like `i - 1`) is left unchecked and doesn't
+// unify into the elements seen so far - it's simply not evidence either way.
+func (a *arrayHelper) checkElement(elementType ArrayType, line int) (diagnostics.Diagnostic, bool) {
+	current := a.getType()
+	if current == AUNKNOWN {
+		a.setType(elementType)
+		return diagnostics.Diagnostic{}, false
+	}
+
+	if current == ACOMPLEX || elementType == AUNKNOWN || elementType == current {
+		return diagnostics.Diagnostic{}, false
+	}
+
+	data := &identification.ArrayData{ElementType: current.persistedType()}
+	diagnostic, ok := data.CheckElement(elementType.persistedType(), line)
+
+	a.setType(ACOMPLEX)
+
+	return diagnostic, ok
+}
+
+// closeArray finishes the array literal at the current level and returns
+// the identification.ArrayData to persist on its ARRAY attribute, so a
+// later index-assignment or read can be checked against it via
+// attribute.CheckArrayElement - mirroring hashHelper.closeHash's *HashData.
+func (a *arrayHelper) closeArray() *identification.ArrayData {
+	data := &identification.ArrayData{ElementType: a.getType().persistedType()}
+
+	a.arrays = a.arrays[:a.levels]
+	a.levels--
+
+	return data
+}
+
+// persistedType converts the transient ArrayType tracked while walking an
+// array literal into the identification.ArrayElementType stored permanently
+// on the attribute, so later index-assignments can be checked against it
+// instead of the type being discarded once the literal finishes traversing.
+func (t ArrayType) persistedType() identification.ArrayElementType {
+	switch t {
+	case AINTEGER:
+		return identification.ArrayElementInteger
+	case ASTRING:
+		return identification.ArrayElementString
+	case ACOMPLEX:
+		return identification.ArrayElementMixed
+	default:
+		return identification.ArrayElementUnknown
+	}
+}
@@ -1,6 +1,10 @@
 package contextual
 
-import "github.com/antlr/antlr4/runtime/Go/antlr"
+import (
+	"github.com/Mario-Jimenez/gocompiler/diagnostics"
+	"github.com/Mario-Jimenez/gocompiler/identification"
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
 
 type HashType int
 
@@ -55,7 +59,57 @@ func (h *hashHelper) getType() HashType {
 	return HCOMPLEX
 }
 
-func (h *hashHelper) closeHash() {
+// checkKey unifies keyType into the hash literal currently being built and
+// reports a diagnostic if it does not match the keys seen so far at this
+// level, the same way arrayHelper.checkElement does for array literals. A
+// key whose own type couldn't be statically determined (e.g. a computed
+// expression like `i + 1`) is left unchecked and doesn't unify into the
+// keys seen so far - it's simply not evidence either way.
+func (h *hashHelper) checkKey(keyType HashType, line int) (diagnostics.Diagnostic, bool) {
+	current := h.getType()
+	if current == HUNKNOWN {
+		h.setType(keyType)
+		return diagnostics.Diagnostic{}, false
+	}
+
+	if current == HCOMPLEX || keyType == HUNKNOWN || keyType == current {
+		return diagnostics.Diagnostic{}, false
+	}
+
+	data := &identification.HashData{KeyType: current.persistedType()}
+	diagnostic, ok := data.CheckKey(keyType.persistedType(), line)
+
+	h.setType(HCOMPLEX)
+
+	return diagnostic, ok
+}
+
+// closeHash finishes the hash literal at the current level and returns the
+// identification.HashData to persist on its HASH attribute, so a later
+// index-assignment or read can be checked against it via
+// attribute.CheckHashKey - mirroring arrayHelper.closeArray's *ArrayData.
+func (h *hashHelper) closeHash() *identification.HashData {
+	data := &identification.HashData{KeyType: h.getType().persistedType()}
+
 	h.hashs = h.hashs[:h.levels]
 	h.levels--
-}
\ No newline at end of file
+
+	return data
+}
+
+// persistedType converts the transient HashType tracked while walking a
+// hash literal into the identification.HashKeyType stored permanently on
+// the attribute, so later index-assignments can be checked against it
+// instead of the type being discarded once the literal finishes traversing.
+func (t HashType) persistedType() identification.HashKeyType {
+	switch t {
+	case HINTEGER:
+		return identification.HashKeyInteger
+	case HSTRING:
+		return identification.HashKeyString
+	case HCOMPLEX:
+		return identification.HashKeyMixed
+	default:
+		return identification.HashKeyUnknown
+	}
+}
@@ -0,0 +1,498 @@
+package contextual
+
+import (
+	"github.com/Mario-Jimenez/gocompiler/diagnostics"
+	"github.com/Mario-Jimenez/gocompiler/identification"
+	"github.com/Mario-Jimenez/gocompiler/parser"
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+// literalKind is the syntactic shape of an expression's leaf value, as far
+// as it can be told without real type inference - enough to check a hash
+// literal's keys or an array literal's elements against each other, and
+// against how they're later indexed.
+type literalKind int
+
+const (
+	literalUnknown literalKind = iota
+	literalInteger
+	literalString
+)
+
+// classification is what every expression-visiting method returns: the
+// kind of value it evaluates to, a more detailed literalKind for hash/array
+// checks, and - for a HASH or ARRAY literal - the persisted data to declare
+// its identifier with.
+type classification struct {
+	kind    identification.ExpressionType
+	literal literalKind
+	data    interface{}
+}
+
+// Visitor declares identifiers, resolves references and checks hash/array
+// literals for consistent key/element types as it walks the tree - the
+// contextual analysis pass between parsing and code generation.
+type Visitor struct {
+	*parser.BaseMonkeyVisitor
+	table  *identification.Table
+	hashes *hashHelper
+	arrays *arrayHelper
+}
+
+// NewVisitor creates a contextual analysis visitor that declares and
+// resolves identifiers against table
+func NewVisitor(table *identification.Table) *Visitor {
+	return &Visitor{
+		BaseMonkeyVisitor: &parser.BaseMonkeyVisitor{},
+		table:             table,
+		hashes:            newHashHelper(),
+		arrays:            newArrayHelper(),
+	}
+}
+
+// Visit overrides the one promoted from BaseMonkeyVisitor so that
+// tree.Accept dispatches back into Visitor's own VisitXxx overrides instead
+// of the embedded base visitor's no-op ones.
+func (v *Visitor) Visit(tree antlr.ParseTree) interface{} {
+	return tree.Accept(v)
+}
+
+// visitExpression visits tree and type-asserts its result back to a
+// classification, the protocol every expression-visiting method here uses
+// to report what it evaluates to.
+func (v *Visitor) visitExpression(tree antlr.ParseTree) classification {
+	result, _ := v.Visit(tree).(classification)
+	return result
+}
+
+/*
+	program: statement* EOF ;
+*/
+
+func (v *Visitor) VisitProgram(ctx *parser.ProgramContext) interface{} {
+	for _, statement := range ctx.AllStatement() {
+		v.Visit(statement)
+	}
+
+	return nil
+}
+
+/*
+	statement: 'let' Identifier '=' expression ';' # letStatement ;
+
+	The identifier is declared with a placeholder attribute before its
+	expression is visited, and the placeholder is backfilled once the
+	value is known, rather than declaring only after visiting - a
+	self-recursive binding (e.g. testdata/medium.monkey's fibonacci)
+	otherwise fails to resolve its own name while its body is walked.
+*/
+
+func (v *Visitor) VisitLetStatement(ctx *parser.LetStatementContext) interface{} {
+	attr := identification.NewAttribute(identification.NEUTRAL, ctx.Identifier().GetSymbol(), nil, literalUnknown.persistedType())
+	v.table.Declare(ctx.Identifier().GetText(), attr)
+
+	value := v.visitExpression(ctx.Expression())
+
+	attr.Complete(value.kind, value.data, value.literal.persistedType())
+
+	return nil
+}
+
+/*
+	statement: 'return' expression ';' # returnStatement ;
+*/
+
+func (v *Visitor) VisitReturnStatement(ctx *parser.ReturnStatementContext) interface{} {
+	v.visitExpression(ctx.Expression())
+	return nil
+}
+
+/*
+	statement:
+		primaryExpression '[' expression ']' '=' expression ';'
+			# indexAssignmentStatement
+		;
+*/
+
+func (v *Visitor) VisitIndexAssignmentStatement(ctx *parser.IndexAssignmentStatementContext) interface{} {
+	line := ctx.GetStart().GetLine()
+
+	key := v.visitExpression(ctx.Expression(0))
+	value := v.visitExpression(ctx.Expression(1))
+
+	attr, ok := v.lookupIndexed(ctx.PrimaryExpression(), line)
+	if !ok {
+		return nil
+	}
+
+	if diagnostic, ok := attr.CheckHashKey(hashTypeOf(key).persistedType(), line); ok {
+		v.table.Report(diagnostic)
+	}
+
+	// unlike a hash's bracket content, an array's bracket content is always
+	// meant to be an integer position rather than a value to type-check -
+	// so the array half of this check runs against the assigned value here,
+	// never against the index key or on a plain read.
+	if diagnostic, ok := attr.CheckArrayElement(arrayElementTypeOf(value).persistedType(), line); ok {
+		v.table.Report(diagnostic)
+	}
+
+	return nil
+}
+
+/*
+	statement: expression ';' # expressionStatement ;
+*/
+
+func (v *Visitor) VisitExpressionStatement(ctx *parser.ExpressionStatementContext) interface{} {
+	v.visitExpression(ctx.Expression())
+	return nil
+}
+
+/*
+	block: '{' statement* '}' ;
+*/
+
+func (v *Visitor) VisitBlock(ctx *parser.BlockContext) interface{} {
+	for _, statement := range ctx.AllStatement() {
+		v.Visit(statement)
+	}
+
+	return nil
+}
+
+/*
+	expression:
+		additionExpression (
+			comparisonFactor additionExpression
+		)* # comparisonTree
+		;
+*/
+
+func (v *Visitor) VisitComparisonTree(ctx *parser.ComparisonTreeContext) interface{} {
+	first := v.visitExpression(ctx.AdditionExpression(0))
+
+	branches := ctx.AllAdditionExpression()
+	for i := 1; i < len(branches); i++ {
+		v.visitExpression(branches[i])
+	}
+
+	if len(branches) > 1 {
+		return classification{}
+	}
+
+	return first
+}
+
+/*
+	primaryExpression: '(' expression ')' # groupedExpression ;
+*/
+
+func (v *Visitor) VisitGroupedExpression(ctx *parser.GroupedExpressionContext) interface{} {
+	return v.Visit(ctx.Expression())
+}
+
+/*
+	primaryExpression:
+		'[' (expression (',' expression)*)? ']' # arrayLiteral
+		;
+*/
+
+func (v *Visitor) VisitArrayLiteral(ctx *parser.ArrayLiteralContext) interface{} {
+	v.arrays.newArray()
+
+	for _, element := range ctx.AllExpression() {
+		value := v.visitExpression(element)
+
+		if diagnostic, ok := v.arrays.checkElement(arrayElementTypeOf(value), ctx.GetStart().GetLine()); ok {
+			v.table.Report(diagnostic)
+		}
+	}
+
+	return classification{kind: identification.ARRAY, data: v.arrays.closeArray()}
+}
+
+/*
+	primaryExpression:
+		'{' (hashPair (',' hashPair)*)? '}' # hashLiteral
+		;
+	hashPair: expression ':' expression ;
+*/
+
+func (v *Visitor) VisitHashLiteral(ctx *parser.HashLiteralContext) interface{} {
+	v.hashes.newHash()
+
+	for _, pair := range ctx.AllHashPair() {
+		key := v.visitExpression(pair.Key())
+		v.visitExpression(pair.Value())
+
+		if diagnostic, ok := v.hashes.checkKey(hashTypeOf(key), ctx.GetStart().GetLine()); ok {
+			v.table.Report(diagnostic)
+		}
+	}
+
+	return classification{kind: identification.HASH, data: v.hashes.closeHash()}
+}
+
+/*
+	primaryExpression:
+		'if' '(' expression ')' block ('else' block)? # ifExpression
+		;
+
+	Each branch is its own lexical scope, so a let bound inside one doesn't
+	leak into the other or into the enclosing scope.
+*/
+
+func (v *Visitor) VisitIfExpression(ctx *parser.IfExpressionContext) interface{} {
+	v.visitExpression(ctx.Expression())
+
+	v.table.OpenScope()
+	v.Visit(ctx.Block(0))
+	v.table.CloseScope()
+
+	if len(ctx.AllBlock()) > 1 {
+		v.table.OpenScope()
+		v.Visit(ctx.Block(1))
+		v.table.CloseScope()
+	}
+
+	return classification{}
+}
+
+/*
+	primaryExpression:
+		'fn' '(' (Identifier (',' Identifier)*)? ')' block # functionLiteral
+		;
+*/
+
+func (v *Visitor) VisitFunctionLiteral(ctx *parser.FunctionLiteralContext) interface{} {
+	v.table.OpenScope()
+
+	for _, id := range ctx.AllIdentifier() {
+		v.table.Declare(id.GetText(), identification.NewAttribute(identification.IDENTIFIER, id.GetSymbol(), nil, literalUnknown.persistedType()))
+	}
+
+	v.Visit(ctx.Block())
+	v.table.CloseScope()
+
+	return classification{kind: identification.FUNCTION}
+}
+
+/*
+	primaryExpression:
+		primaryExpression '(' (expression (',' expression)*)? ')' # callExpression
+		;
+*/
+
+func (v *Visitor) VisitCallExpression(ctx *parser.CallExpressionContext) interface{} {
+	v.Visit(ctx.PrimaryExpression())
+
+	for _, arg := range ctx.AllExpression() {
+		v.visitExpression(arg)
+	}
+
+	return classification{}
+}
+
+/*
+	primaryExpression:
+		primaryExpression '[' expression ']' # indexExpression
+		;
+*/
+
+func (v *Visitor) VisitIndexExpression(ctx *parser.IndexExpressionContext) interface{} {
+	line := ctx.GetStart().GetLine()
+	key := v.visitExpression(ctx.Expression())
+
+	if attr, ok := v.lookupIndexed(ctx.PrimaryExpression(), line); ok {
+		// a hash's bracket content is its key, so it's checked against the
+		// recorded key type here. An array's bracket content is always
+		// meant to be an integer position rather than a value to
+		// type-check - that only happens for the assigned value on
+		// `arr[i] = v`, see VisitIndexAssignmentStatement - so a plain read
+		// like `arr[i]` has nothing further to check.
+		if diagnostic, ok := attr.CheckHashKey(hashTypeOf(key).persistedType(), line); ok {
+			v.table.Report(diagnostic)
+		}
+	}
+
+	return classification{}
+}
+
+// indexable is the subset of identification's attribute behavior needed to
+// check a hash/array index against its declared type. It's named here
+// rather than referring to *identification.attribute directly because that
+// type is unexported - satisfied implicitly by whatever Table.Lookup
+// returns.
+type indexable interface {
+	CheckHashKey(keyType identification.HashKeyType, line int) (diagnostics.Diagnostic, bool)
+	CheckArrayElement(elementType identification.ArrayElementType, line int) (diagnostics.Diagnostic, bool)
+}
+
+// lookupIndexed resolves target back to its declared attribute if target is
+// a plain identifier, e.g. the `arr` in `arr[0]`. Indexing anything else
+// (e.g. a chained `f()[0]`) isn't resolvable back to a declared attribute,
+// so it's left unchecked.
+func (v *Visitor) lookupIndexed(target antlr.ParseTree, line int) (indexable, bool) {
+	identifier, ok := target.(*parser.IdentifierLiteralContext)
+	if !ok {
+		return nil, false
+	}
+
+	return v.table.Lookup(identifier.GetText(), line)
+}
+
+/*
+	additionExpression:
+		multiplicationExpression (
+			additionFactor multiplicationExpression
+		)* # additionTree
+		;
+*/
+
+func (v *Visitor) VisitAdditionTree(ctx *parser.AdditionTreeContext) interface{} {
+	first := v.visitExpression(ctx.MultiplicationExpression(0))
+
+	branches := ctx.AllMultiplicationExpression()
+	literal := first.literal
+	for i := 1; i < len(branches); i++ {
+		next := v.visitExpression(branches[i])
+		literal = combineLiteral(literal, next.literal)
+	}
+
+	if len(branches) > 1 {
+		return classification{literal: literal}
+	}
+
+	return first
+}
+
+/*
+	multiplicationExpression:
+		primaryExpression (
+			multiplicationFactor primaryExpression
+		)* # multiplicationTree
+		;
+*/
+
+func (v *Visitor) VisitMultiplicationTree(ctx *parser.MultiplicationTreeContext) interface{} {
+	first := v.visitExpression(ctx.PrimaryExpression(0))
+
+	branches := ctx.AllPrimaryExpression()
+	literal := first.literal
+	for i := 1; i < len(branches); i++ {
+		next := v.visitExpression(branches[i])
+		literal = combineLiteral(literal, next.literal)
+	}
+
+	if len(branches) > 1 {
+		return classification{literal: literal}
+	}
+
+	return first
+}
+
+// combineLiteral folds a chain of homogeneous binary operations (addition,
+// multiplication) into a single literalKind: the shared kind if every
+// operand agrees (e.g. int + int stays int, so `h[a+b]` still checks
+// as an integer key against h's recorded key type), literalUnknown otherwise - which
+// hashTypeOf/arrayElementTypeOf then treat as "can't tell, skip the check"
+// rather than a mismatch.
+func combineLiteral(a, b literalKind) literalKind {
+	if a == b {
+		return a
+	}
+
+	return literalUnknown
+}
+
+func (v *Visitor) VisitIntegerLiteral(ctx *parser.IntegerLiteralContext) interface{} {
+	return classification{literal: literalInteger}
+}
+
+func (v *Visitor) VisitStringLiteral(ctx *parser.StringLiteralContext) interface{} {
+	return classification{literal: literalString}
+}
+
+func (v *Visitor) VisitBooleanLiteral(ctx *parser.BooleanLiteralContext) interface{} {
+	return classification{}
+}
+
+// VisitIdentifierLiteral resolves the identifier against the table,
+// propagating whatever it was declared as so a `let` that just aliases it
+// (`let b = a;`) or an index expression built on it classifies the same way
+// - including its literalKind, so indexing a hash/array with a variable key
+// (`h[k]`) is checked against what k actually holds instead of defaulting
+// to literalUnknown.
+func (v *Visitor) VisitIdentifierLiteral(ctx *parser.IdentifierLiteralContext) interface{} {
+	attr, ok := v.table.Lookup(ctx.GetText(), ctx.GetStart().GetLine())
+	if !ok {
+		return classification{}
+	}
+
+	return classification{kind: attr.GetType(), literal: literalKindFrom(attr.GetLiteral()), data: attr.GetData()}
+}
+
+// persistedType converts a literalKind into the identification.LiteralType
+// stored permanently on the attribute it was declared with, the same way
+// HashType.persistedType does for a hash literal's key type.
+func (k literalKind) persistedType() identification.LiteralType {
+	switch k {
+	case literalInteger:
+		return identification.LiteralInteger
+	case literalString:
+		return identification.LiteralString
+	default:
+		return identification.LiteralUnknown
+	}
+}
+
+// literalKindFrom converts an attribute's persisted LiteralType back into
+// the transient literalKind classification carries, the inverse of
+// literalKind.persistedType.
+func literalKindFrom(t identification.LiteralType) literalKind {
+	switch t {
+	case identification.LiteralInteger:
+		return literalInteger
+	case identification.LiteralString:
+		return literalString
+	default:
+		return literalUnknown
+	}
+}
+
+// hashTypeOf converts a checked expression's literalKind into the
+// transient HashType hashHelper tracks while walking a hash literal. A
+// literalUnknown value (e.g. a computed expression or function parameter)
+// maps to HUNKNOWN rather than HCOMPLEX - it isn't evidence the hash is
+// mixed, just that nothing can be checked.
+func hashTypeOf(value classification) HashType {
+	switch value.literal {
+	case literalInteger:
+		return HINTEGER
+	case literalString:
+		return HSTRING
+	case literalUnknown:
+		return HUNKNOWN
+	default:
+		return HCOMPLEX
+	}
+}
+
+// arrayElementTypeOf converts a checked expression's literalKind into the
+// transient ArrayType arrayHelper tracks while walking an array literal. A
+// literalUnknown value (e.g. a computed expression or function parameter)
+// maps to AUNKNOWN rather than ACOMPLEX - it isn't evidence the array is
+// mixed, just that nothing can be checked.
+func arrayElementTypeOf(value classification) ArrayType {
+	switch value.literal {
+	case literalInteger:
+		return AINTEGER
+	case literalString:
+		return ASTRING
+	case literalUnknown:
+		return AUNKNOWN
+	default:
+		return ACOMPLEX
+	}
+}